@@ -9,6 +9,7 @@ rm -rf internal/content/system
 cp -r system internal/content/system
 
 echo "==> Building agentspack..."
-go build -o agentspack .
+VERSION="$(git describe --tags --always --dirty 2>/dev/null || echo dev)"
+go build -ldflags "-X github.com/agentspack/agentspack/internal/version.Version=$VERSION" -o agentspack .
 
 echo "==> Done! Binary created at: $SCRIPT_DIR/agentspack"
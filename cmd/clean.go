@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/agentspack/agentspack/internal/generator"
+	"github.com/agentspack/agentspack/internal/manifest"
+	"github.com/agentspack/agentspack/internal/wizard"
+	"github.com/charmbracelet/huh"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cleanOutput string
+	cleanForce  bool
+)
+
+var cleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Remove generated files that the configuration no longer produces",
+	Long: `Remove the files listed in the output directory's manifest
+(.agentspack/manifest.json) that agentspack.yaml (or --profile) no longer
+generates, e.g. after renaming an agent or deselecting a tech stack.
+
+Files that were edited by hand since they were generated are only removed
+after confirmation, or with --force. Use --dry-run to list what would be removed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runClean()
+	},
+}
+
+func init() {
+	flags := cleanCmd.Flags()
+	flags.StringVarP(&cleanOutput, "output", "o", "", "directory to clean (defaults to the configured output directory)")
+	flags.BoolVarP(&cleanForce, "force", "f", false, "also remove files that were edited by hand, without asking")
+	flags.BoolVar(&dryRun, "dry-run", false, "list the files that would be removed without removing them")
+	rootCmd.AddCommand(cleanCmd)
+}
+
+func runClean() {
+	cfg, systemDir, err := loadSavedConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if cleanOutput != "" {
+		cfg.OutputDir = wizard.ExpandPath(cleanOutput)
+	}
+
+	previous, err := manifest.Load(cfg.OutputDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	absOutputDir, err := filepath.Abs(cfg.OutputDir)
+	if err != nil {
+		absOutputDir = cfg.OutputDir
+	}
+	if previous == nil {
+		fmt.Printf("Nothing to clean: %s has no %s\n", absOutputDir, manifest.FileName)
+		return
+	}
+
+	plan, err := generator.New(cfg, systemDir).Plan()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	stale := previous.Stale(plan)
+	if len(stale) == 0 {
+		fmt.Printf("Nothing to clean in %s\n", absOutputDir)
+		return
+	}
+
+	fmt.Printf("Cleaning %s\n", absOutputDir)
+	confirm := confirmRemove
+	if cleanForce {
+		confirm = func(string) bool { return true }
+	}
+	kept, err := manifest.Prune(cfg.OutputDir, stale, confirm, dryRun)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if dryRun {
+		return
+	}
+
+	// Drop the removed files from the manifest
+	var files []manifest.Entry
+	for _, entry := range previous.Files {
+		if _, ok := plan.Get(entry.Path); ok {
+			files = append(files, entry)
+		}
+	}
+	previous.Files = files
+	previous.Keep(kept)
+	if err := previous.Save(cfg.OutputDir); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to write manifest: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// confirmRemove asks whether a generated file that was edited by hand should
+// be removed. It answers no when there is no terminal to ask on.
+func confirmRemove(path string) bool {
+	if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+
+	remove := false
+	err := huh.NewConfirm().
+		Title(fmt.Sprintf("%s was edited by hand. Remove it anyway?", path)).
+		Affirmative("Remove").
+		Negative("Keep").
+		Value(&remove).
+		Run()
+	return err == nil && remove
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/agentspack/agentspack/internal/config"
+	"github.com/agentspack/agentspack/internal/diff"
+	"github.com/agentspack/agentspack/internal/generator"
+	"github.com/agentspack/agentspack/internal/manifest"
+	"github.com/agentspack/agentspack/internal/providers"
+	"github.com/agentspack/agentspack/internal/wizard"
+	"github.com/spf13/cobra"
+)
+
+var diffOutput string
+
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Show how the generated files differ from the files on disk",
+	Long: `Render agentspack.yaml (or --profile) in memory and compare the result
+with the files in the output directory. Modified files are shown as unified
+diffs, followed by the files that would be added and the orphaned files:
+files in provider directories or in the manifest of a previous run that the
+configuration no longer produces. Nothing is written.
+
+The exit status is 0 when the output is up to date, 1 when it differs and 2
+on errors, so the command can be used as a pre-commit hook or CI check.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runDiff()
+	},
+}
+
+func init() {
+	diffCmd.Flags().StringVarP(&diffOutput, "output", "o", "", "directory to compare against (defaults to the configured output directory)")
+	rootCmd.AddCommand(diffCmd)
+}
+
+func runDiff() {
+	cfg, systemDir, err := loadSavedConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+	if diffOutput != "" {
+		cfg.OutputDir = wizard.ExpandPath(diffOutput)
+	}
+
+	plan, err := generator.New(cfg, systemDir).Plan()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+
+	// Files the previous run generated are owned as well, wherever they are
+	previous, err := manifest.Load(cfg.OutputDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+	var ownedFiles []string
+	for _, entry := range previous.Stale(plan) {
+		ownedFiles = append(ownedFiles, entry.Path)
+	}
+
+	// The home directory also holds the user's own agents and commands, so
+	// only files the manifest records count as orphaned there
+	ownedDirs := providers.OwnedDirs(cfg.Providers, plan)
+	if cfg.IsUserScope() {
+		ownedDirs = nil
+	}
+	result, err := diff.Compare(plan, cfg.OutputDir, ownedDirs, ownedFiles)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+
+	absOutputDir, err := filepath.Abs(cfg.OutputDir)
+	if err != nil {
+		absOutputDir = cfg.OutputDir
+	}
+
+	if !result.HasChanges() {
+		fmt.Printf("%s is up to date (%d files)\n", absOutputDir, len(result.Unchanged))
+		return
+	}
+
+	fmt.Printf("Comparing with %s\n\n", absOutputDir)
+	for _, m := range result.Modified {
+		fmt.Print(diff.Unified("a/"+m.File.Path, "b/"+m.File.Path, string(m.Current), string(m.Desired)))
+	}
+	if len(result.Modified) > 0 {
+		fmt.Println()
+	}
+
+	if len(result.Added) > 0 {
+		fmt.Println("Added:")
+		for _, file := range result.Added {
+			fmt.Printf("  + %s\n", file.Path)
+		}
+		fmt.Println()
+	}
+
+	if len(result.Orphaned) > 0 {
+		fmt.Println("Orphaned (no longer generated):")
+		for _, orphan := range result.Orphaned {
+			fmt.Printf("  - %s\n", orphan)
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("%d modified, %d added, %d orphaned\n", len(result.Modified), len(result.Added), len(result.Orphaned))
+	os.Exit(1)
+}
+
+// loadSavedConfig loads the configuration selected by --profile or the
+// project config file, without running the wizard, and validates it
+// against the templates. It returns the configuration and the system directory.
+func loadSavedConfig() (*wizard.Config, string, error) {
+	var cfg *wizard.Config
+	var err error
+	switch {
+	case profileName != "":
+		cfg, err = config.LoadProfile(profileName)
+	case config.Exists(configPath):
+		cfg, err = config.Load(configPath)
+	default:
+		return nil, "", fmt.Errorf("%s not found (run 'agentspack init' first or pass --profile)", configPath)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	systemDir, err := findSystemDir()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to find executable path: %w", err)
+	}
+
+	tmpl, err := loadTemplates(systemDir)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := applyDetectedStacks(cfg, tmpl.registry); err != nil {
+		return nil, "", err
+	}
+
+	if err := validateConfig(cfg, tmpl); err != nil {
+		if profileName != "" {
+			return nil, "", fmt.Errorf("invalid profile %q: %w", profileName, err)
+		}
+		return nil, "", fmt.Errorf("invalid %s: %w", configPath, err)
+	}
+
+	if err := applyScope(cfg); err != nil {
+		return nil, "", err
+	}
+	return cfg, systemDir, nil
+}
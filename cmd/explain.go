@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/agentspack/agentspack/internal/manifest"
+	"github.com/agentspack/agentspack/internal/output"
+	"github.com/agentspack/agentspack/internal/provenance"
+	"github.com/spf13/cobra"
+)
+
+var explainCmd = &cobra.Command{
+	Use:   "explain <generated-file>[:line]",
+	Short: "Show which templates a generated file or line came from",
+	Long: `Look up a generated file in the manifest of the output directory it
+belongs to (.agentspack/manifest.json) and report the provider that
+generated it, how the provider transformed its templates, and which
+template each range of lines was copied from. With :line, only that line
+is explained.
+
+Example:
+  agentspack explain .claude/rules/backend.md:42`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Fprintf(os.Stderr, "Error: expected exactly one file, got %d arguments\n", len(args))
+			os.Exit(1)
+		}
+		if err := runExplain(args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+}
+
+func runExplain(arg string) error {
+	file, line := splitLineSuffix(arg)
+
+	outputDir, m, entry, err := findManifestEntry(file)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(filepath.Join(outputDir, filepath.FromSlash(entry.Path)))
+	if err != nil {
+		return err
+	}
+
+	// Source map lines count from the start of the managed block
+	offset := 0
+	if entry.Managed {
+		begin := bytes.Index(data, []byte(output.BlockBegin))
+		if begin == -1 {
+			return fmt.Errorf("%s no longer has an agentspack block", file)
+		}
+		offset = bytes.Count(data[:begin], []byte("\n")) + 1
+	}
+
+	state, err := entry.State(outputDir)
+	if err != nil {
+		return err
+	}
+
+	if line == 0 {
+		printFileExplanation(entry, m, offset)
+	} else if err := printLineExplanation(file, line, data, entry, offset); err != nil {
+		return err
+	}
+
+	if state == manifest.Edited {
+		fmt.Printf("\nNote: %s was edited since it was generated, so lines may have moved\n", entry.Path)
+	}
+	return nil
+}
+
+// printFileExplanation prints the provider, transformation and source map of a generated file
+func printFileExplanation(entry manifest.Entry, m *manifest.Manifest, offset int) {
+	fmt.Printf("%s (%s, agentspack %s)\n", entry.Path, entry.Provider, m.Version)
+	if entry.Transform != "" {
+		fmt.Printf("  %s\n", entry.Transform)
+	}
+	if entry.Managed {
+		fmt.Printf("  Only the agentspack block (lines %d-%d) is generated\n", offset+1, offset+lastLine(entry.SourceMap))
+	}
+
+	if len(entry.SourceMap) == 0 {
+		fmt.Println("\nNo source map was recorded; regenerate to record one")
+		return
+	}
+
+	fmt.Println("\nLines:")
+	for _, span := range entry.SourceMap {
+		lines := formatRange(offset+span.Start, offset+span.End)
+		if span.Source == "" {
+			fmt.Printf("  %-9s added by %s\n", lines, entry.Provider)
+			continue
+		}
+		fmt.Printf("  %-9s %s:%s\n", lines, span.Source, formatRange(span.SourceLine, span.SourceLine+span.End-span.Start))
+	}
+}
+
+// printLineExplanation prints where a single line of a generated file came from
+func printLineExplanation(file string, line int, data []byte, entry manifest.Entry, offset int) error {
+	lines := strings.Split(string(data), "\n")
+	if line > len(lines) {
+		return fmt.Errorf("%s has only %d lines", file, len(lines))
+	}
+
+	fmt.Printf("%s:%d (%s)\n", entry.Path, line, entry.Provider)
+	fmt.Printf("  > %s\n", lines[line-1])
+
+	span, ok := provenance.Lookup(entry.SourceMap, line-offset)
+	switch {
+	case !ok && entry.Managed:
+		fmt.Println("  Written by hand, outside the agentspack block")
+	case !ok:
+		fmt.Println("  Not part of the generated content")
+	case span.Source == "":
+		fmt.Printf("  Added by %s\n", entry.Provider)
+	default:
+		fmt.Printf("  Copied from %s:%d\n", span.Source, span.SourceLine+line-offset-span.Start)
+	}
+	if ok && entry.Transform != "" {
+		fmt.Printf("  Transformation: %s\n", entry.Transform)
+	}
+	return nil
+}
+
+// findManifestEntry finds the manifest that lists a generated file, searching
+// the file's directory and its parents
+func findManifestEntry(file string) (string, *manifest.Manifest, manifest.Entry, error) {
+	absFile, err := filepath.Abs(file)
+	if err != nil {
+		return "", nil, manifest.Entry{}, err
+	}
+	if _, err := os.Stat(absFile); err != nil {
+		return "", nil, manifest.Entry{}, err
+	}
+
+	for dir := filepath.Dir(absFile); ; dir = filepath.Dir(dir) {
+		m, err := manifest.Load(dir)
+		if err != nil {
+			return "", nil, manifest.Entry{}, err
+		}
+		if m != nil {
+			rel, err := filepath.Rel(dir, absFile)
+			if err == nil {
+				if entry, ok := m.Get(filepath.ToSlash(rel)); ok {
+					return dir, m, entry, nil
+				}
+			}
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			break
+		}
+	}
+	return "", nil, manifest.Entry{}, fmt.Errorf("%s is not listed in any agentspack manifest (%s) above it", file, manifest.FileName)
+}
+
+// splitLineSuffix splits "file:line" into the file and line; line is 0 without a suffix
+func splitLineSuffix(arg string) (string, int) {
+	i := strings.LastIndex(arg, ":")
+	if i == -1 {
+		return arg, 0
+	}
+	line, err := strconv.Atoi(arg[i+1:])
+	if err != nil || line < 1 {
+		return arg, 0
+	}
+	return arg[:i], line
+}
+
+// formatRange formats a range of lines (e.g. "3-8", or "3" for a single line)
+func formatRange(start, end int) string {
+	if start == end {
+		return strconv.Itoa(start)
+	}
+	return fmt.Sprintf("%d-%d", start, end)
+}
+
+// lastLine returns the last line a source map covers
+func lastLine(spans []output.Span) int {
+	if len(spans) == 0 {
+		return 0
+	}
+	return spans[len(spans)-1].End
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/agentspack/agentspack/internal/export"
+	"github.com/agentspack/agentspack/internal/generator"
+	"github.com/agentspack/agentspack/internal/manifest"
+	"github.com/agentspack/agentspack/internal/wizard"
+)
+
+// runExport writes the generated files as an archive or patch instead of
+// writing them to the output directory
+func runExport(gen *generator.Generator, cfg *wizard.Config) {
+	if !slices.Contains(export.Formats, format) {
+		fmt.Fprintf(os.Stderr, "Error: unknown format %q (available: %s)\n", format, strings.Join(export.Formats, ", "))
+		os.Exit(1)
+	}
+
+	path := exportTo
+	if path == "" {
+		path = "agentspack" + export.Extension(format)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	count, err := gen.Export(format, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if format == export.FormatPatch {
+		absOutputDir, err := filepath.Abs(cfg.OutputDir)
+		if err != nil {
+			absOutputDir = cfg.OutputDir
+		}
+		fmt.Printf("Wrote %s (%d files changed against %s)\n", path, count, absOutputDir)
+		fmt.Printf("Apply it from the target directory with: git apply %s\n", path)
+		return
+	}
+	fmt.Printf("Wrote %s (%d files and %s)\n", path, count, manifest.FileName)
+}
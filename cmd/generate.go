@@ -0,0 +1,348 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/agentspack/agentspack/internal/config"
+	"github.com/agentspack/agentspack/internal/detect"
+	"github.com/agentspack/agentspack/internal/export"
+	"github.com/agentspack/agentspack/internal/providers"
+	"github.com/agentspack/agentspack/internal/stacks"
+	"github.com/agentspack/agentspack/internal/wizard"
+	"github.com/charmbracelet/huh"
+	"github.com/spf13/cobra"
+)
+
+// generateFlags holds the flag values for the generate command
+var generateFlags struct {
+	providers  []string
+	stacks     []string
+	claudeMode string
+	geminiCtx  string
+	base       bool
+	output     string
+	templates  string
+	project    string
+	inferGlobs bool
+	scope      string
+	provenance bool
+	dedupe     bool
+}
+
+var generateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate files non-interactively from flags",
+	Long: `Generate provider-specific files without running the interactive wizard.
+All selections are taken from flags, which makes this command suitable for
+scripts, Makefiles and CI. With --profile, the profile provides the starting
+selections and any flags given explicitly override it. When no --stack is
+given, the tech stacks are detected from the project directory.
+
+Example:
+  agentspack generate --provider cursor,claude-code --stack react --output .`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runGenerate(cmd)
+	},
+}
+
+func init() {
+	flags := generateCmd.Flags()
+	flags.StringSliceVarP(&generateFlags.providers, "provider", "p", nil, "providers to generate for (e.g. cursor,claude-code,codex)")
+	flags.StringSliceVarP(&generateFlags.stacks, "stack", "s", nil, "tech stacks to include templates for (e.g. backend,react)")
+	flags.StringVar(&generateFlags.claudeMode, "claude-mode", string(wizard.ClaudeCodeModeRules), "how Claude Code tech stack guidelines are generated (rules or skills)")
+	flags.StringVar(&generateFlags.geminiCtx, "gemini-context", string(wizard.GeminiContextGemini), "which context file Gemini CLI reads (gemini for GEMINI.md, or agents for a shared AGENTS.md)")
+	flags.BoolVar(&generateFlags.base, "base", true, "generate the base instructions file (CLAUDE.md, AGENTS.md, etc.)")
+	flags.StringVarP(&generateFlags.output, "output", "o", wizard.DefaultOutputDir, "where to write the generated files")
+	flags.StringVar(&generateFlags.scope, "scope", string(wizard.ScopeProject), "where to install: project (the output directory) or user (~/.claude, ~/.codex, ~/.cursor)")
+	flags.StringVar(&generateFlags.templates, "templates", "", "local system directory to read templates from (defaults to auto-detect, then embedded)")
+	flags.StringVar(&generateFlags.project, "project", "", "project directory to detect stacks and infer globs from (defaults to the working directory)")
+	flags.BoolVar(&generateFlags.inferGlobs, "infer-globs", false, "scope stack rules to globs inferred from the project layout")
+	flags.BoolVar(&generateFlags.provenance, "provenance", false, "start generated markdown files with a comment naming their source templates")
+	flags.BoolVar(&generateFlags.dedupe, "dedupe", false, "write shared templates once, in AGENTS.md, and have CLAUDE.md import it instead of repeating them")
+	flags.BoolVar(&dryRun, "dry-run", false, "print the files that would be generated, with their sizes and source templates, without writing anything")
+	flags.BoolVar(&prune, "prune", false, "remove files the previous run generated that are no longer produced (asks before removing files edited by hand)")
+	flags.StringVar(&format, "format", export.FormatDir, "output format: dir, zip, tar.gz or patch (a git apply-able diff against the output directory)")
+	flags.StringVar(&exportTo, "export-to", "", "file to write the zip, tar.gz or patch to (defaults to agentspack.<format>)")
+
+	rootCmd.AddCommand(generateCmd)
+}
+
+func runGenerate(cmd *cobra.Command) {
+	cfg := &wizard.Config{
+		Providers:      generateFlags.providers,
+		TechStacks:     generateFlags.stacks,
+		GenerateBase:   generateFlags.base,
+		OutputDir:      wizard.ExpandPath(generateFlags.output),
+		ClaudeCodeMode: wizard.ClaudeCodeMode(generateFlags.claudeMode),
+		GeminiContext:  wizard.GeminiContext(generateFlags.geminiCtx),
+		InferGlobs:     generateFlags.inferGlobs,
+		Scope:          wizard.Scope(generateFlags.scope),
+		Provenance:     generateFlags.provenance,
+		Dedupe:         generateFlags.dedupe,
+	}
+	if generateFlags.project != "" {
+		cfg.ProjectDir = wizard.ExpandPath(generateFlags.project)
+	}
+
+	if profileName != "" {
+		profileConfig, err := config.LoadProfile(profileName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		applyChangedFlags(cmd, profileConfig)
+		cfg = profileConfig
+	}
+	// The scope may come from the profile, so check it once both are merged
+	if cmd.Flags().Changed("output") && cfg.IsUserScope() {
+		fmt.Fprintf(os.Stderr, "Error: --output can't be combined with the user scope, which installs into the home directory\n")
+		os.Exit(1)
+	}
+
+	systemDir, err := resolveTemplatesDir(generateFlags.templates)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	tmpl, err := loadTemplates(systemDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := applyDetectedStacks(cfg, tmpl.registry); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := validateConfig(cfg, tmpl); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	wizard.PrintSummary(cfg)
+	runGeneration(cfg, systemDir)
+}
+
+// applyDetectedStacks fills in the tech stacks detected in the project
+// directory when none were selected, and reports the evidence for each
+func applyDetectedStacks(cfg *wizard.Config, registry *stacks.Registry) error {
+	if len(cfg.TechStacks) > 0 {
+		return nil
+	}
+
+	detections, err := detect.Stacks(projectDir(cfg), registry.All())
+	if err != nil {
+		return fmt.Errorf("failed to detect tech stacks: %w", err)
+	}
+	if len(detections) == 0 {
+		return nil
+	}
+
+	// Reported on stderr, so the output of commands like diff stays clean
+	fmt.Fprintln(os.Stderr, "Detected tech stacks:")
+	for _, line := range detect.Describe(detections) {
+		fmt.Fprintf(os.Stderr, "  %s\n", line)
+	}
+	cfg.TechStacks = detect.Names(detections)
+	return nil
+}
+
+// projectDir returns the project a configuration targets, defaulting to the working directory
+func projectDir(cfg *wizard.Config) string {
+	if cfg.ProjectDir == "" {
+		return "."
+	}
+	return cfg.ProjectDir
+}
+
+// applyChangedFlags overrides a configuration with the flags that were
+// explicitly set on the command line
+func applyChangedFlags(cmd *cobra.Command, cfg *wizard.Config) {
+	flags := cmd.Flags()
+	if flags.Changed("provider") {
+		cfg.Providers = generateFlags.providers
+	}
+	if flags.Changed("stack") {
+		cfg.TechStacks = generateFlags.stacks
+	}
+	if flags.Changed("claude-mode") {
+		cfg.ClaudeCodeMode = wizard.ClaudeCodeMode(generateFlags.claudeMode)
+	}
+	if flags.Changed("gemini-context") {
+		cfg.GeminiContext = wizard.GeminiContext(generateFlags.geminiCtx)
+	}
+	if flags.Changed("base") {
+		cfg.GenerateBase = generateFlags.base
+	}
+	if flags.Changed("output") {
+		cfg.OutputDir = wizard.ExpandPath(generateFlags.output)
+	}
+	if flags.Changed("project") {
+		cfg.ProjectDir = wizard.ExpandPath(generateFlags.project)
+	}
+	if flags.Changed("infer-globs") {
+		cfg.InferGlobs = generateFlags.inferGlobs
+	}
+	if flags.Changed("scope") {
+		cfg.Scope = wizard.Scope(generateFlags.scope)
+	}
+	if flags.Changed("provenance") {
+		cfg.Provenance = generateFlags.provenance
+	}
+	if flags.Changed("dedupe") {
+		cfg.Dedupe = generateFlags.dedupe
+	}
+}
+
+// resolveTemplatesDir returns the explicitly requested templates directory,
+// or falls back to the usual system directory lookup when none was given.
+// Templates are read as system/... from the directory's parent, so it must
+// be named system.
+func resolveTemplatesDir(templates string) (string, error) {
+	if templates == "" {
+		return findSystemDir()
+	}
+
+	dir := filepath.Clean(wizard.ExpandPath(templates))
+	if filepath.Base(dir) != "system" {
+		return "", fmt.Errorf("templates directory %q must be named system (e.g. my-templates/system)", templates)
+	}
+	if !isValidSystemDir(dir) {
+		return "", fmt.Errorf("templates directory %q is not a valid agentspack system directory (missing base/base.md)", templates)
+	}
+	return dir, nil
+}
+
+// validateConfig checks a configuration from flags or a config file for unknown or missing values
+func validateConfig(cfg *wizard.Config, tmpl *templateSet) error {
+	if len(cfg.Providers) == 0 {
+		return fmt.Errorf("at least one provider is required (available: %s)", strings.Join(availableProviderNames(), ", "))
+	}
+	for _, name := range cfg.Providers {
+		if _, ok := providers.Get(name); !ok {
+			return fmt.Errorf("unknown provider %q (available: %s)", name, strings.Join(availableProviderNames(), ", "))
+		}
+	}
+
+	if len(cfg.TechStacks) == 0 {
+		return fmt.Errorf("at least one tech stack is required and none were detected (available: %s)", strings.Join(tmpl.registry.Names(), ", "))
+	}
+	for _, stack := range cfg.TechStacks {
+		if _, ok := tmpl.registry.Get(stack); !ok {
+			return fmt.Errorf("unknown tech stack %q (available: %s)", stack, strings.Join(tmpl.registry.Names(), ", "))
+		}
+	}
+
+	if err := tmpl.validateSelections(cfg); err != nil {
+		return err
+	}
+
+	if err := validateProviderOptions(cfg); err != nil {
+		return err
+	}
+
+	if cfg.OutputDir == "" {
+		return fmt.Errorf("output directory cannot be empty")
+	}
+
+	switch cfg.Scope {
+	case "", wizard.ScopeProject:
+	case wizard.ScopeUser:
+		if cfg.SyncToGitHub {
+			return fmt.Errorf("GitHub sync isn't available with the %s scope", wizard.ScopeUser)
+		}
+	default:
+		return fmt.Errorf("unknown scope %q (available: %s, %s)", cfg.Scope, wizard.ScopeProject, wizard.ScopeUser)
+	}
+
+	if cfg.SyncToGitHub {
+		switch cfg.SyncMode {
+		case wizard.SyncModePR, wizard.SyncModeMerge:
+		default:
+			return fmt.Errorf("unknown sync mode %q (available: %s, %s)", cfg.SyncMode, wizard.SyncModePR, wizard.SyncModeMerge)
+		}
+	}
+
+	return nil
+}
+
+// applyScope points the output directory at the home directory for user-level installs
+func applyScope(cfg *wizard.Config) error {
+	if !cfg.IsUserScope() {
+		return nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("failed to find the home directory: %w", err)
+	}
+	cfg.OutputDir = home
+	return nil
+}
+
+// validateProviderOptions checks the provider-specific settings of the
+// selected providers, setting those left unset to their default
+func validateProviderOptions(cfg *wizard.Config) error {
+	for _, info := range providers.All() {
+		if !slices.Contains(cfg.Providers, info.Name) {
+			continue
+		}
+		for _, option := range info.Options {
+			value := option.Get(cfg)
+			if value == "" && len(option.Choices) > 0 {
+				value = option.Choices[0].Value
+				option.Set(cfg, value)
+			}
+			var available []string
+			for _, choice := range option.Choices {
+				available = append(available, choice.Value)
+			}
+			if !slices.Contains(available, value) {
+				return fmt.Errorf("unknown %s %q for %s (available: %s)", option.Key, value, info.Name, strings.Join(available, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// providerChoices builds the wizard's provider list and follow-up questions from the registry
+func providerChoices() []wizard.ProviderChoice {
+	var choices []wizard.ProviderChoice
+	for _, info := range providers.All() {
+		choice := wizard.ProviderChoice{
+			Name:        info.Name,
+			DisplayName: info.DisplayName,
+			Description: info.Description,
+		}
+		for _, option := range info.Options {
+			question := wizard.Question{
+				Title:       option.Title,
+				Description: option.Description,
+				Get:         option.Get,
+				Set:         option.Set,
+			}
+			for _, c := range option.Choices {
+				question.Options = append(question.Options, huh.NewOption(c.Label, c.Value))
+			}
+			choice.Questions = append(choice.Questions, question)
+		}
+		choices = append(choices, choice)
+	}
+	return choices
+}
+
+// availableProviderNames returns the sorted names of all registered providers
+func availableProviderNames() []string {
+	names := make([]string, 0, len(providers.Registry))
+	for name := range providers.Registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
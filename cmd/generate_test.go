@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/agentspack/agentspack/internal/wizard"
+)
+
+// writeSystemDir writes a minimal templates directory with a backend stack at dir
+func writeSystemDir(t *testing.T, dir string) {
+	t.Helper()
+	for name, data := range map[string]string{
+		"base/base.md":                      "# Base\n",
+		"rules/backend/stack.yaml":          "name: backend\ndisplay_name: Backend\n",
+		"rules/backend/database_queries.md": "## Queries\n",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestResolveTemplatesDir(t *testing.T) {
+	root := t.TempDir()
+	systemDir := filepath.Join(root, "pack", "system")
+	writeSystemDir(t, systemDir)
+	writeSystemDir(t, filepath.Join(root, "sys"))
+
+	dir, err := resolveTemplatesDir(systemDir + string(filepath.Separator))
+	if err != nil || dir != systemDir {
+		t.Fatalf("Expected %s, got %q (%v)", systemDir, dir, err)
+	}
+
+	// The stacks are read from the templates that were given
+	tmpl, err := loadTemplates(dir)
+	if err != nil {
+		t.Fatalf("Failed to load templates: %v", err)
+	}
+	if names := tmpl.registry.Names(); len(names) != 1 || names[0] != "backend" {
+		t.Errorf("Expected only the backend stack, got %v", names)
+	}
+
+	for _, templates := range []string{filepath.Join(root, "sys"), filepath.Join(root, "pack")} {
+		if _, err := resolveTemplatesDir(templates); err == nil {
+			t.Errorf("Expected %s to be rejected", templates)
+		}
+	}
+}
+
+func TestValidateConfigRejectsUnknownValues(t *testing.T) {
+	systemDir := filepath.Join(t.TempDir(), "system")
+	writeSystemDir(t, systemDir)
+	tmpl, err := loadTemplates(systemDir)
+	if err != nil {
+		t.Fatalf("Failed to load templates: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		cfg  wizard.Config
+		want string
+	}{
+		{"valid", wizard.Config{OutputDir: ".", Providers: []string{"cursor"}, TechStacks: []string{"backend"}}, ""},
+		{"unknown provider", wizard.Config{OutputDir: ".", Providers: []string{"vim"}, TechStacks: []string{"backend"}}, `unknown provider "vim"`},
+		{"unknown stack", wizard.Config{OutputDir: ".", Providers: []string{"cursor"}, TechStacks: []string{"vue"}}, `unknown tech stack "vue"`},
+		{"no stacks", wizard.Config{OutputDir: ".", Providers: []string{"cursor"}}, "at least one tech stack is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateConfig(&tt.cfg, tmpl)
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("Expected the config to be valid, got %v", err)
+			case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+				t.Errorf("Expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/agentspack/agentspack/internal/config"
+	"github.com/agentspack/agentspack/internal/wizard"
+	"github.com/spf13/cobra"
+)
+
+var initForce bool
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Run the wizard once and save the selections to agentspack.yaml",
+	Long: `Run the interactive wizard and write the selections to agentspack.yaml.
+Commit the file so every developer and CI job generates the same output;
+running agentspack afterwards regenerates from it without prompting.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runInit()
+	},
+}
+
+func init() {
+	initCmd.Flags().BoolVarP(&initForce, "force", "f", false, "overwrite an existing config file")
+	rootCmd.AddCommand(initCmd)
+}
+
+func runInit() {
+	if config.Exists(configPath) && !initForce {
+		fmt.Fprintf(os.Stderr, "Error: %s already exists (use --force to overwrite)\n", configPath)
+		os.Exit(1)
+	}
+
+	fmt.Println("Welcome to agentspack!")
+	fmt.Println()
+
+	systemDir, err := findSystemDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error finding executable path: %v\n", err)
+		os.Exit(1)
+	}
+
+	tmpl, err := loadTemplates(systemDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	cfg, err := runWizardForm(tmpl)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	wizard.PrintSummary(cfg)
+
+	if err := config.Save(configPath, cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to write %s: %v\n", configPath, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Wrote %s\n", configPath)
+	fmt.Println("Run 'agentspack' to generate files from it.")
+}
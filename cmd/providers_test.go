@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/agentspack/agentspack/internal/content"
+	"github.com/agentspack/agentspack/internal/output"
+	"github.com/agentspack/agentspack/internal/providers"
+	"github.com/agentspack/agentspack/internal/wizard"
+)
+
+// plainProvider is a provider that doesn't describe itself
+type plainProvider struct{}
+
+func (p *plainProvider) Name() string { return "plain" }
+
+func (p *plainProvider) Generate(config *wizard.Config, fs content.FileSystem, plan *output.Plan) error {
+	return nil
+}
+
+func TestProviderChoicesFollowRegistry(t *testing.T) {
+	providers.Register(&plainProvider{})
+	t.Cleanup(func() { delete(providers.Registry, "plain") })
+
+	choices := make(map[string]wizard.ProviderChoice)
+	for _, choice := range providerChoices() {
+		choices[choice.Name] = choice
+	}
+	for _, name := range availableProviderNames() {
+		if _, ok := choices[name]; !ok {
+			t.Errorf("Expected the registered provider %s to be offered", name)
+		}
+	}
+
+	if plain := choices["plain"]; plain.DisplayName != "plain" || len(plain.Questions) != 0 {
+		t.Errorf("Expected a provider without a description to be listed by name, got %+v", plain)
+	}
+
+	// Claude Code's option becomes a follow-up question that sets the config
+	claude := choices["claude-code"]
+	if claude.DisplayName != "Claude Code" || len(claude.Questions) != 1 {
+		t.Fatalf("Expected Claude Code with one question, got %+v", claude)
+	}
+	question := claude.Questions[0]
+	var values []string
+	for _, option := range question.Options {
+		values = append(values, option.Value)
+	}
+	if strings.Join(values, ",") != "rules,skills" {
+		t.Errorf("Expected the rules and skills choices, got %v", values)
+	}
+	cfg := &wizard.Config{}
+	question.Set(cfg, "skills")
+	if cfg.ClaudeCodeMode != wizard.ClaudeCodeModeSkills || question.Get(cfg) != "skills" {
+		t.Errorf("Expected the question to set the Claude Code mode, got %q", cfg.ClaudeCodeMode)
+	}
+}
+
+func TestValidateProviderOptions(t *testing.T) {
+	// Options left empty get their first choice
+	cfg := &wizard.Config{Providers: []string{"claude-code", "gemini"}}
+	if err := validateProviderOptions(cfg); err != nil {
+		t.Fatalf("Expected the defaults to be valid, got %v", err)
+	}
+	if cfg.ClaudeCodeMode != wizard.ClaudeCodeModeRules || cfg.GeminiContext != wizard.GeminiContextGemini {
+		t.Errorf("Expected the default choices, got %q and %q", cfg.ClaudeCodeMode, cfg.GeminiContext)
+	}
+
+	cfg = &wizard.Config{Providers: []string{"claude-code"}, ClaudeCodeMode: "agents"}
+	if err := validateProviderOptions(cfg); err == nil || !strings.Contains(err.Error(), "claude_code_mode") {
+		t.Errorf("Expected an unknown choice to be rejected, got %v", err)
+	}
+
+	// Options of providers that aren't selected are ignored
+	cfg = &wizard.Config{Providers: []string{"claude-code"}, GeminiContext: "other"}
+	if err := validateProviderOptions(cfg); err != nil {
+		t.Errorf("Expected the options of unselected providers to be ignored, got %v", err)
+	}
+}
@@ -5,10 +5,15 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/agentspack/agentspack/internal/config"
+	"github.com/agentspack/agentspack/internal/detect"
+	"github.com/agentspack/agentspack/internal/export"
 	"github.com/agentspack/agentspack/internal/generator"
 	_ "github.com/agentspack/agentspack/internal/providers" // Register providers
 	"github.com/agentspack/agentspack/internal/syncer"
+	"github.com/agentspack/agentspack/internal/version"
 	"github.com/agentspack/agentspack/internal/wizard"
+	"github.com/charmbracelet/huh"
 	"github.com/spf13/cobra"
 )
 
@@ -17,12 +22,45 @@ var rootCmd = &cobra.Command{
 	Short: "Generate provider-specific AI agent context files",
 	Long: `agentspack is a CLI tool that helps developers generate provider-specific
 AI agent context files (e.g., Cursor, Claude Code, Codex) from a built-in
-library of markdown templates.`,
+library of markdown templates.
+
+When an agentspack.yaml file exists, agentspack regenerates from it. The
+interactive wizard runs only when no config file exists or --interactive
+is passed. Use --profile to generate from a named profile instead.`,
 	Run: func(cmd *cobra.Command, args []string) {
+		if !interactive && profileName != "" {
+			runFromProfile()
+			return
+		}
+		if !interactive && config.Exists(configPath) {
+			runFromConfigFile()
+			return
+		}
 		runWizard()
 	},
 }
 
+var (
+	configPath  string
+	profileName string
+	interactive bool
+	dryRun      bool
+	prune       bool
+	format      string
+	exportTo    string
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", config.FileName, "path to the project config file")
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "named profile to generate from (see ~/.config/agentspack/profiles and .agentspack/profiles)")
+	rootCmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "run the interactive wizard even if a config file exists")
+	rootCmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the files that would be generated, with their sizes and source templates, without writing anything")
+	rootCmd.Flags().BoolVar(&prune, "prune", false, "remove files the previous run generated that are no longer produced (asks before removing files edited by hand)")
+	rootCmd.Flags().StringVar(&format, "format", export.FormatDir, "output format: dir, zip, tar.gz or patch (a git apply-able diff against the output directory)")
+	rootCmd.Flags().StringVar(&exportTo, "export-to", "", "file to write the zip, tar.gz or patch to (defaults to agentspack.<format>)")
+	rootCmd.Version = version.Version
+}
+
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
@@ -34,28 +72,239 @@ func runWizard() {
 	fmt.Println("Welcome to agentspack!")
 	fmt.Println()
 
-	config, err := wizard.Run()
+	systemDir, err := findSystemDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error finding executable path: %v\n", err)
+		os.Exit(1)
+	}
+
+	tmpl, err := loadTemplates(systemDir)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	wizard.PrintSummary(config)
+	cfg, err := runWizardForm(tmpl)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 
-	// Determine system directory (relative to binary location for now)
-	// In MVP, we assume the system folder is next to the binary
-	execPath, err := os.Executable()
+	// The previous run's answers can be reused as they were saved, naming
+	// stacks, providers or templates that no longer exist
+	if err := validateConfig(cfg, tmpl); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	wizard.PrintSummary(cfg)
+	runGeneration(cfg, systemDir)
+}
+
+// runFromConfigFile regenerates the output from the project config file
+func runFromConfigFile() {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	systemDir, err := findSystemDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error finding executable path: %v\n", err)
+		os.Exit(1)
+	}
+
+	tmpl, err := loadTemplates(systemDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := applyDetectedStacks(cfg, tmpl.registry); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := validateConfig(cfg, tmpl); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid %s: %v\n", configPath, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Using %s\n", configPath)
+	wizard.PrintSummary(cfg)
+	runGeneration(cfg, systemDir)
+}
+
+// runFromProfile generates the output from a named profile
+func runFromProfile() {
+	cfg, err := config.LoadProfile(profileName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	systemDir, err := findSystemDir()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error finding executable path: %v\n", err)
 		os.Exit(1)
 	}
 
-	// Helper to check if a directory is a valid agentspack system directory
-	// (must contain base/base.md to be valid)
-	isValidSystemDir := func(dir string) bool {
-		baseFile := filepath.Join(dir, "base", "base.md")
-		info, err := os.Stat(baseFile)
-		return err == nil && !info.IsDir()
+	tmpl, err := loadTemplates(systemDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := applyDetectedStacks(cfg, tmpl.registry); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := validateConfig(cfg, tmpl); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid profile %q: %v\n", profileName, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Using profile %s\n", profileName)
+	wizard.PrintSummary(cfg)
+	runGeneration(cfg, systemDir)
+}
+
+// runWizardForm runs the interactive wizard. The forms are pre-filled from,
+// in order of precedence, --profile, the previous run into the target output
+// directory, and the project config file. When no profile was given, the
+// available profiles are offered on the first screen.
+func runWizardForm(tmpl *templateSet) (*wizard.Config, error) {
+	opts := wizard.RunOptions{
+		Providers:   providerChoices(),
+		LoadProfile: config.LoadProfile,
+	}
+	if err := tmpl.fillWizardOptions(&opts); err != nil {
+		return nil, err
+	}
+
+	if config.Exists(configPath) {
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			return nil, err
+		}
+		opts.Defaults = cfg
+	}
+
+	if profileName != "" {
+		cfg, err := config.LoadProfile(profileName)
+		if err != nil {
+			return nil, err
+		}
+		opts.Defaults = cfg
+	}
+
+	outputDir := wizard.DefaultOutputDir
+	if opts.Defaults != nil {
+		outputDir = opts.Defaults.OutputDir
+	}
+	previous, err := config.LoadState(wizard.ExpandPath(outputDir))
+	if err != nil {
+		return nil, err
+	}
+	if previous != nil {
+		opts.Previous = previous
+		if profileName == "" {
+			opts.Defaults = previous
+		}
+	}
+
+	// Preselect the tech stacks found in the project when nothing else chose them
+	if opts.Defaults == nil || len(opts.Defaults.TechStacks) == 0 {
+		dir := "."
+		if opts.Defaults != nil {
+			dir = projectDir(opts.Defaults)
+		}
+		detections, err := detect.Stacks(dir, tmpl.registry.All())
+		if err != nil {
+			return nil, err
+		}
+		if len(detections) > 0 {
+			if opts.Defaults == nil {
+				opts.Defaults = wizard.DefaultConfig()
+			}
+			defaults := *opts.Defaults
+			defaults.TechStacks = detect.Names(detections)
+			opts.Defaults = &defaults
+			opts.DetectedStacks = detect.Describe(detections)
+		}
+	}
+
+	if profileName == "" {
+		profiles, err := config.ListProfiles()
+		if err != nil {
+			return nil, err
+		}
+		for _, profile := range profiles {
+			label := profile.Name
+			if profile.Description != "" {
+				label = fmt.Sprintf("%s — %s", profile.Name, profile.Description)
+			}
+			opts.Profiles = append(opts.Profiles, huh.NewOption(label, profile.Name))
+		}
+	}
+
+	return wizard.RunWithOptions(opts)
+}
+
+// runGeneration runs the generator and, if requested, the GitHub syncer.
+// With --dry-run it only prints the planned files. It exits the process on failure.
+func runGeneration(cfg *wizard.Config, systemDir string) {
+	if err := applyScope(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	gen := generator.New(cfg, systemDir)
+	if dryRun {
+		if err := gen.DryRun(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if format != export.FormatDir {
+		runExport(gen, cfg)
+		return
+	}
+
+	// Run the generator
+	gen.Prune = prune
+	gen.ConfirmRemove = confirmRemove
+	if err := gen.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Remember the selections so the next wizard run can pre-fill them
+	if err := config.SaveState(cfg); err != nil {
+		fmt.Printf("Warning: failed to save wizard state: %v\n", err)
+	}
+
+	// Run the syncer if GitHub sync was requested
+	if cfg.SyncToGitHub {
+		sync := syncer.New(cfg, cfg.OutputDir)
+		if err := sync.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Sync error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+}
+
+// findSystemDir locates a local system directory to read templates from.
+// It returns an empty string when none is found, which forces embedded mode.
+func findSystemDir() (string, error) {
+	// Determine system directory (relative to binary location for now)
+	// In MVP, we assume the system folder is next to the binary
+	execPath, err := os.Executable()
+	if err != nil {
+		return "", err
 	}
 
 	systemDir := filepath.Join(filepath.Dir(execPath), "system")
@@ -77,19 +326,13 @@ func runWizard() {
 		systemDir = ""
 	}
 
-	// Run the generator
-	gen := generator.New(config, systemDir)
-	if err := gen.Run(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
-	}
+	return systemDir, nil
+}
 
-	// Run the syncer if GitHub sync was requested
-	if config.SyncToGitHub {
-		sync := syncer.New(config, config.OutputDir)
-		if err := sync.Run(); err != nil {
-			fmt.Fprintf(os.Stderr, "Sync error: %v\n", err)
-			os.Exit(1)
-		}
-	}
+// isValidSystemDir checks if a directory is a valid agentspack system directory
+// (must contain base/base.md to be valid)
+func isValidSystemDir(dir string) bool {
+	baseFile := filepath.Join(dir, "base", "base.md")
+	info, err := os.Stat(baseFile)
+	return err == nil && !info.IsDir()
 }
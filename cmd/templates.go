@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/agentspack/agentspack/internal/catalog"
+	"github.com/agentspack/agentspack/internal/content"
+	"github.com/agentspack/agentspack/internal/stacks"
+	"github.com/agentspack/agentspack/internal/wizard"
+	"github.com/charmbracelet/huh"
+)
+
+// templateSet is the template content a run generates from
+type templateSet struct {
+	fs       content.FileSystem
+	registry *stacks.Registry
+}
+
+// loadTemplates reads the templates in systemDir, or the embedded templates
+// when systemDir is empty, including the tech stacks they declare
+func loadTemplates(systemDir string) (*templateSet, error) {
+	fs, _ := content.GetFileSystem(systemDir)
+	registry, err := stacks.Load(fs)
+	if err != nil {
+		return nil, err
+	}
+	return &templateSet{fs: fs, registry: registry}, nil
+}
+
+// fillWizardOptions offers the templates' tech stacks, agents, workflows and rules in the wizard
+func (t *templateSet) fillWizardOptions(opts *wizard.RunOptions) error {
+	for _, stack := range t.registry.All() {
+		opts.TechStacks = append(opts.TechStacks, huh.NewOption(stack.DisplayName, stack.Name))
+	}
+
+	agents, workflows, rules, err := t.catalog()
+	if err != nil {
+		return err
+	}
+	opts.Agents = itemOptions(agents)
+	opts.Workflows = itemOptions(workflows)
+	opts.Rules = itemOptions(rules)
+	return nil
+}
+
+// validateSelections checks that included and excluded IDs name existing templates
+func (t *templateSet) validateSelections(cfg *wizard.Config) error {
+	agents, workflows, rules, err := t.catalog()
+	if err != nil {
+		return err
+	}
+
+	for _, check := range []struct {
+		kind      string
+		selection wizard.Selection
+		items     []catalog.Item
+	}{
+		{"agent", cfg.Agents, agents},
+		{"workflow", cfg.Workflows, workflows},
+		{"rule", cfg.Rules, rules},
+	} {
+		available := catalog.IDs(check.items)
+		for _, id := range append(append([]string{}, check.selection.Include...), check.selection.Exclude...) {
+			if !containsSameID(available, id) {
+				return fmt.Errorf("unknown %s %q (available: %s)", check.kind, id, strings.Join(available, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// catalog lists the templates' agents, workflows and rules
+func (t *templateSet) catalog() (agents, workflows, rules []catalog.Item, err error) {
+	if agents, err = catalog.Agents(t.fs); err != nil {
+		return nil, nil, nil, err
+	}
+	if workflows, err = catalog.Workflows(t.fs); err != nil {
+		return nil, nil, nil, err
+	}
+	if rules, err = catalog.Rules(t.fs); err != nil {
+		return nil, nil, nil, err
+	}
+	return agents, workflows, rules, nil
+}
+
+// itemOptions converts catalog items into wizard options labelled by ID
+func itemOptions(items []catalog.Item) []huh.Option[string] {
+	options := make([]huh.Option[string], len(items))
+	for i, item := range items {
+		options[i] = huh.NewOption(item.ID, item.ID)
+	}
+	return options
+}
+
+// containsSameID checks if id names one of the available templates
+func containsSameID(available []string, id string) bool {
+	for _, candidate := range available {
+		if wizard.SameID(candidate, id) {
+			return true
+		}
+	}
+	return false
+}
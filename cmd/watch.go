@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/agentspack/agentspack/internal/generator"
+	"github.com/agentspack/agentspack/internal/watch"
+	"github.com/agentspack/agentspack/internal/wizard"
+	"github.com/spf13/cobra"
+)
+
+var (
+	watchOutput   string
+	watchInterval time.Duration
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Regenerate whenever a template in the local system/ directory changes",
+	Long: `Generate from agentspack.yaml (or --profile), then poll the local system/
+directory for changes and regenerate. Only files whose content changed are
+written, and a short summary is printed for every change. Files that are no
+longer generated are left on disk for 'agentspack clean'.
+
+Polling needs no OS support for file notifications. Press Ctrl+C to stop.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runWatch()
+	},
+}
+
+func init() {
+	flags := watchCmd.Flags()
+	flags.StringVarP(&watchOutput, "output", "o", "", "directory to generate into (defaults to the configured output directory)")
+	flags.DurationVar(&watchInterval, "interval", watch.DefaultInterval, "how often to check the templates for changes")
+	rootCmd.AddCommand(watchCmd)
+}
+
+func runWatch() {
+	cfg, systemDir, err := loadSavedConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if systemDir == "" {
+		fmt.Fprintf(os.Stderr, "Error: no local system/ directory found next to the binary or in the working directory; watch only works with local templates\n")
+		os.Exit(1)
+	}
+	if watchOutput != "" {
+		cfg.OutputDir = wizard.ExpandPath(watchOutput)
+	}
+
+	gen := generator.New(cfg, systemDir)
+	plan, changes, err := gen.Update(nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	absOutputDir, err := filepath.Abs(cfg.OutputDir)
+	if err != nil {
+		absOutputDir = cfg.OutputDir
+	}
+	fmt.Printf("Generated %d files into %s\n", len(changes.Added), absOutputDir)
+	printSkipped(changes.Skipped)
+	fmt.Printf("Watching %s for changes (Ctrl+C to stop)\n", systemDir)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	onChange := func(changed []string) {
+		fmt.Printf("\n[%s] %s\n", time.Now().Format("15:04:05"), describeChangedTemplates(changed))
+
+		next, changes, err := gen.Update(plan)
+		if err != nil {
+			// Keep watching; the next save usually fixes it
+			fmt.Fprintf(os.Stderr, "  Error: %v\n", err)
+			return
+		}
+		plan = next
+		printChanges(changes)
+	}
+	onError := func(err error) {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	if err := watch.Poll(ctx, systemDir, "system", watchInterval, onChange, onError); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println("\nStopped watching")
+}
+
+// describeChangedTemplates summarizes the templates that changed
+func describeChangedTemplates(changed []string) string {
+	if len(changed) == 1 {
+		return changed[0] + " changed"
+	}
+	return fmt.Sprintf("%d templates changed: %s", len(changed), strings.Join(changed, ", "))
+}
+
+// printChanges prints what a regeneration wrote
+func printChanges(changes generator.Changes) {
+	if changes.IsEmpty() {
+		fmt.Println("  No generated files changed")
+		return
+	}
+	for _, path := range changes.Updated {
+		fmt.Printf("  Updated: %s\n", path)
+	}
+	for _, path := range changes.Added {
+		fmt.Printf("  Added: %s\n", path)
+	}
+	for _, path := range changes.Removed {
+		fmt.Printf("  No longer generated: %s (run 'agentspack clean' to remove)\n", path)
+	}
+	printSkipped(changes.Skipped)
+}
+
+// printSkipped lists files a user-level install left untouched
+func printSkipped(skipped []string) {
+	for _, path := range skipped {
+		fmt.Printf("  Skipped: %s (not generated by agentspack, or edited since)\n", path)
+	}
+}
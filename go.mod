@@ -5,6 +5,7 @@ go 1.25.5
 require (
 	github.com/charmbracelet/huh v0.8.0
 	github.com/spf13/cobra v1.10.2
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
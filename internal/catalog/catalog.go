@@ -0,0 +1,167 @@
+package catalog
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/agentspack/agentspack/internal/content"
+)
+
+// Item is an individually selectable agent, workflow or rule template
+type Item struct {
+	ID   string // e.g. "cloudflare-workers-developer", "planning", "backend/database_queries"
+	Path string // Source path in the content filesystem
+}
+
+// AgentID returns the ID of an agent template: its file name without the
+// extension, with underscores replaced (e.g. "visual-storyteller")
+func AgentID(sourcePath string) string {
+	name := strings.TrimSuffix(path.Base(sourcePath), ".md")
+	return strings.ReplaceAll(name, "_", "-")
+}
+
+// WorkflowID returns the ID of a workflow: the name of its folder under system/workflows
+func WorkflowID(workflowDir string) string {
+	return path.Base(workflowDir)
+}
+
+// RuleID returns the ID of a rule template: its path under system/rules
+// without the extension (e.g. "backend/database_queries")
+func RuleID(sourcePath string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(sourcePath, "system/rules/"), ".md")
+}
+
+// Agents lists the agent templates, sorted by ID
+func Agents(fs content.FileSystem) ([]Item, error) {
+	files, err := AgentFiles(fs)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]Item, 0, len(files))
+	for _, file := range files {
+		items = append(items, Item{ID: AgentID(file), Path: file})
+	}
+	sortItems(items)
+	return items, nil
+}
+
+// AgentFiles returns the paths of the agent templates in system/agents and
+// its subdirectories at any depth, listing each folder's files before its
+// subfolders. Agents are selected by file name, so two agents with the same
+// file name in different folders are an error.
+func AgentFiles(fs content.FileSystem) ([]string, error) {
+	if _, err := fs.Stat("system/agents"); err != nil {
+		return nil, nil
+	}
+
+	var files []string
+	if err := collectMarkdown(fs, "system/agents", &files); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]string, len(files))
+	for _, file := range files {
+		id := AgentID(file)
+		if other, ok := seen[id]; ok {
+			return nil, fmt.Errorf("agents %s and %s have the same ID %q; rename one of them", other, file, id)
+		}
+		seen[id] = file
+	}
+	return files, nil
+}
+
+// Workflows lists the workflow folders, sorted by ID
+func Workflows(fs content.FileSystem) ([]Item, error) {
+	if _, err := fs.Stat("system/workflows"); err != nil {
+		return nil, nil
+	}
+
+	entries, err := fs.ReadDir("system/workflows")
+	if err != nil {
+		return nil, err
+	}
+
+	var items []Item
+	for _, entry := range entries {
+		if entry.IsDir() {
+			dir := path.Join("system/workflows", entry.Name())
+			items = append(items, Item{ID: WorkflowID(dir), Path: dir})
+		}
+	}
+	sortItems(items)
+	return items, nil
+}
+
+// Rules lists every rule template under system/rules, sorted by ID
+func Rules(fs content.FileSystem) ([]Item, error) {
+	var items []Item
+	if err := collectRules(fs, "system/rules", &items); err != nil {
+		return nil, err
+	}
+	sortItems(items)
+	return items, nil
+}
+
+// IDs returns the IDs of the given items
+func IDs(items []Item) []string {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	return ids
+}
+
+// collectRules recursively adds the markdown files below dir
+func collectRules(fs content.FileSystem, dir string, items *[]Item) error {
+	entries, err := fs.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		entryPath := path.Join(dir, entry.Name())
+		if entry.IsDir() {
+			if err := collectRules(fs, entryPath, items); err != nil {
+				return err
+			}
+		} else if strings.HasSuffix(entry.Name(), ".md") {
+			*items = append(*items, Item{ID: RuleID(entryPath), Path: entryPath})
+		}
+	}
+	return nil
+}
+
+// collectMarkdown adds the markdown files in dir, then those of its
+// subdirectories
+func collectMarkdown(fs content.FileSystem, dir string, files *[]string) error {
+	entries, err := fs.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	var subdirs []string
+	for _, entry := range entries {
+		entryPath := path.Join(dir, entry.Name())
+		if entry.IsDir() {
+			subdirs = append(subdirs, entryPath)
+		} else if strings.HasSuffix(entry.Name(), ".md") {
+			*files = append(*files, entryPath)
+		}
+	}
+	for _, subdir := range subdirs {
+		if err := collectMarkdown(fs, subdir, files); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sortItems sorts items by ID
+func sortItems(items []Item) {
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].ID < items[j].ID
+	})
+}
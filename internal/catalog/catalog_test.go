@@ -0,0 +1,91 @@
+package catalog
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/agentspack/agentspack/internal/content"
+	"github.com/agentspack/agentspack/internal/wizard"
+)
+
+// writeTemplates writes the given templates to a temp dir and returns it as a file system
+func writeTemplates(t *testing.T, templates map[string]string) content.FileSystem {
+	t.Helper()
+	root := t.TempDir()
+	for name, data := range templates {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	return content.NewLocalFS(root)
+}
+
+func TestCatalogIDs(t *testing.T) {
+	fs := writeTemplates(t, map[string]string{
+		"system/agents/visual_storyteller.md":                   "# Stories\n",
+		"system/agents/backend/cloudflare-workers-developer.md": "# Workers\n",
+		"system/workflows/planning/01_write_prd.md":             "# PRD\n",
+		"system/rules/global/coding_style.md":                   "## Coding style\n",
+		"system/rules/backend/stack.yaml":                       "name: backend\n",
+		"system/rules/backend/database_queries.md":              "## Queries\n",
+	})
+
+	agents, err := Agents(fs)
+	if err != nil {
+		t.Fatalf("Failed to list agents: %v", err)
+	}
+	workflows, err := Workflows(fs)
+	if err != nil {
+		t.Fatalf("Failed to list workflows: %v", err)
+	}
+	rules, err := Rules(fs)
+	if err != nil {
+		t.Fatalf("Failed to list rules: %v", err)
+	}
+
+	for _, tt := range []struct {
+		kind  string
+		items []Item
+		want  []string
+	}{
+		{"agents", agents, []string{"cloudflare-workers-developer", "visual-storyteller"}},
+		{"workflows", workflows, []string{"planning"}},
+		{"rules", rules, []string{"backend/database_queries", "global/coding_style"}},
+	} {
+		if got := IDs(tt.items); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Expected %s %v, got %v", tt.kind, tt.want, got)
+		}
+	}
+
+	// Selections match IDs with underscores and hyphens alike
+	selection := wizard.Selection{Include: []string{"visual_storyteller", "cloudflare-workers-developer"}, Exclude: []string{"cloudflare_workers_developer"}}
+	var allowed []string
+	for _, agent := range agents {
+		if selection.Allows(agent.ID) {
+			allowed = append(allowed, agent.ID)
+		}
+	}
+	if want := []string{"visual-storyteller"}; !reflect.DeepEqual(allowed, want) {
+		t.Errorf("Expected the selection to allow %v, got %v", want, allowed)
+	}
+}
+
+func TestAgentsRejectsDuplicateIDs(t *testing.T) {
+	fs := writeTemplates(t, map[string]string{
+		"system/agents/backend/api_developer.md":  "# Backend API\n",
+		"system/agents/frontend/api-developer.md": "# Frontend API\n",
+	})
+
+	if _, err := Agents(fs); err == nil {
+		t.Error("Expected agents with the same file name in different folders to be rejected")
+	}
+	if _, err := AgentFiles(fs); err == nil {
+		t.Error("Expected AgentFiles to reject them too, since providers load agents from it")
+	}
+}
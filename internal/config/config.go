@@ -0,0 +1,255 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/agentspack/agentspack/internal/wizard"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the default name of the project config file
+const FileName = "agentspack.yaml"
+
+// CurrentVersion is the config file format version written by this build
+const CurrentVersion = 1
+
+// File is the on-disk representation of agentspack.yaml
+type File struct {
+	Version        int      `yaml:"version"`
+	Providers      []string `yaml:"providers"`
+	TechStacks     []string `yaml:"stacks"`
+	ClaudeCodeMode string   `yaml:"claude_code_mode,omitempty"`
+	GeminiContext  string   `yaml:"gemini_context,omitempty"`
+	GenerateBase   *bool    `yaml:"base,omitempty"`
+	OutputDir      string   `yaml:"output,omitempty"`
+	Scope          string   `yaml:"scope,omitempty"`
+	Sync           *Sync    `yaml:"sync,omitempty"`
+	ProjectDir     string   `yaml:"project,omitempty"`
+	InferGlobs     *bool    `yaml:"infer_globs,omitempty"`
+	Provenance     *bool    `yaml:"provenance,omitempty"`
+	Dedupe         *bool    `yaml:"dedupe,omitempty"`
+
+	Globs map[string]Globs `yaml:"globs,omitempty"`
+
+	Agents    *Selection `yaml:"agents,omitempty"`
+	Workflows *Selection `yaml:"workflows,omitempty"`
+	Rules     *Selection `yaml:"rules,omitempty"`
+}
+
+// Selection includes or excludes individual templates by ID
+type Selection struct {
+	Include []string `yaml:"include,omitempty"`
+	Exclude []string `yaml:"exclude,omitempty"`
+}
+
+// Globs replaces or extends the globs a tech stack's rules are scoped to
+type Globs struct {
+	Replace []string `yaml:"replace,omitempty"`
+	Add     []string `yaml:"add,omitempty"`
+}
+
+// Sync holds the GitHub sync settings of a config file
+type Sync struct {
+	Enabled      bool   `yaml:"enabled"`
+	Mode         string `yaml:"mode,omitempty"`
+	TargetBranch string `yaml:"target_branch,omitempty"`
+}
+
+// Exists checks if a config file exists at path
+func Exists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// Load reads a config file and converts it into a wizard configuration
+func Load(path string) (*wizard.Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// Parse decodes config file content into a wizard configuration
+func Parse(data []byte) (*wizard.Config, error) {
+	var file File
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	if file.Version == 0 {
+		return nil, fmt.Errorf("missing version (expected version: %d)", CurrentVersion)
+	}
+	if file.Version > CurrentVersion {
+		return nil, fmt.Errorf("unsupported version %d (this build supports up to %d)", file.Version, CurrentVersion)
+	}
+
+	return file.toConfig(), nil
+}
+
+// Save writes a wizard configuration to a config file
+func Save(path string, config *wizard.Config) error {
+	data, err := Marshal(config)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Marshal encodes a wizard configuration as config file content
+func Marshal(config *wizard.Config) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(fromConfig(config)); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// toConfig converts the file representation into a wizard configuration,
+// applying the same defaults as the wizard for omitted fields
+func (f *File) toConfig() *wizard.Config {
+	config := &wizard.Config{
+		Providers:      f.Providers,
+		TechStacks:     f.TechStacks,
+		GenerateBase:   true,
+		OutputDir:      wizard.DefaultOutputDir,
+		ClaudeCodeMode: wizard.ClaudeCodeModeRules,
+	}
+
+	if f.GenerateBase != nil {
+		config.GenerateBase = *f.GenerateBase
+	}
+	if f.OutputDir != "" {
+		config.OutputDir = f.OutputDir
+	}
+	config.OutputDir = wizard.ExpandPath(config.OutputDir)
+	if f.Scope != "" {
+		config.Scope = wizard.Scope(f.Scope)
+	}
+	if f.ClaudeCodeMode != "" {
+		config.ClaudeCodeMode = wizard.ClaudeCodeMode(f.ClaudeCodeMode)
+	}
+	if f.GeminiContext != "" {
+		config.GeminiContext = wizard.GeminiContext(f.GeminiContext)
+	}
+
+	if f.ProjectDir != "" {
+		config.ProjectDir = wizard.ExpandPath(f.ProjectDir)
+	}
+	if f.InferGlobs != nil {
+		config.InferGlobs = *f.InferGlobs
+	}
+	if f.Provenance != nil {
+		config.Provenance = *f.Provenance
+	}
+	if f.Dedupe != nil {
+		config.Dedupe = *f.Dedupe
+	}
+	for stack, globs := range f.Globs {
+		if config.StackGlobs == nil {
+			config.StackGlobs = make(map[string]wizard.GlobOverride)
+		}
+		config.StackGlobs[stack] = wizard.GlobOverride{Replace: globs.Replace, Add: globs.Add}
+	}
+
+	config.Agents = f.Agents.toSelection()
+	config.Workflows = f.Workflows.toSelection()
+	config.Rules = f.Rules.toSelection()
+
+	if f.Sync != nil && f.Sync.Enabled {
+		config.SyncToGitHub = true
+		config.SyncMode = wizard.SyncModePR
+		config.TargetBranch = wizard.DefaultTargetBranch
+		if f.Sync.Mode != "" {
+			config.SyncMode = wizard.SyncMode(f.Sync.Mode)
+		}
+		if f.Sync.TargetBranch != "" {
+			config.TargetBranch = f.Sync.TargetBranch
+		}
+	}
+
+	return config
+}
+
+// fromConfig converts a wizard configuration into its file representation
+func fromConfig(config *wizard.Config) *File {
+	generateBase := config.GenerateBase
+	file := &File{
+		Version:        CurrentVersion,
+		Providers:      config.Providers,
+		TechStacks:     config.TechStacks,
+		ClaudeCodeMode: string(config.ClaudeCodeMode),
+		GeminiContext:  string(config.GeminiContext),
+		GenerateBase:   &generateBase,
+		OutputDir:      config.OutputDir,
+		ProjectDir:     config.ProjectDir,
+	}
+
+	if config.IsUserScope() {
+		// The output directory follows from the scope
+		file.Scope = string(config.Scope)
+		file.OutputDir = ""
+	}
+
+	if config.InferGlobs {
+		inferGlobs := true
+		file.InferGlobs = &inferGlobs
+	}
+	if config.Provenance {
+		provenance := true
+		file.Provenance = &provenance
+	}
+	if config.Dedupe {
+		dedupe := true
+		file.Dedupe = &dedupe
+	}
+	for stack, override := range config.StackGlobs {
+		if file.Globs == nil {
+			file.Globs = make(map[string]Globs)
+		}
+		file.Globs[stack] = Globs{Replace: override.Replace, Add: override.Add}
+	}
+
+	file.Agents = fromSelection(config.Agents)
+	file.Workflows = fromSelection(config.Workflows)
+	file.Rules = fromSelection(config.Rules)
+
+	if config.SyncToGitHub {
+		file.Sync = &Sync{
+			Enabled:      true,
+			Mode:         string(config.SyncMode),
+			TargetBranch: config.TargetBranch,
+		}
+	}
+
+	return file
+}
+
+// toSelection converts an optional file selection into a wizard selection
+func (s *Selection) toSelection() wizard.Selection {
+	if s == nil {
+		return wizard.Selection{}
+	}
+	return wizard.Selection{Include: s.Include, Exclude: s.Exclude}
+}
+
+// fromSelection converts a wizard selection into its file representation,
+// leaving it out when it allows everything
+func fromSelection(s wizard.Selection) *Selection {
+	if s.IsEmpty() {
+		return nil
+	}
+	return &Selection{Include: s.Include, Exclude: s.Exclude}
+}
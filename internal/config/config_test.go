@@ -0,0 +1,107 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/agentspack/agentspack/internal/wizard"
+)
+
+func TestSaveAndLoadRoundTrip(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "agentspack-config-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	original := &wizard.Config{
+		Providers:      []string{"cursor", "claude-code"},
+		TechStacks:     []string{"react"},
+		GenerateBase:   false,
+		OutputDir:      "out",
+		ClaudeCodeMode: wizard.ClaudeCodeModeSkills,
+		SyncToGitHub:   true,
+		SyncMode:       wizard.SyncModeMerge,
+		TargetBranch:   "develop",
+		Agents:         wizard.Selection{Exclude: []string{"cloudflare-workers-developer"}},
+		Workflows:      wizard.Selection{Include: []string{"development"}},
+	}
+
+	path := filepath.Join(tmpDir, FileName)
+	if err := Save(path, original); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if !reflect.DeepEqual(original, loaded) {
+		t.Errorf("Loaded config does not match saved config:\n got: %+v\nwant: %+v", loaded, original)
+	}
+}
+
+func TestParseAppliesDefaults(t *testing.T) {
+	config, err := Parse([]byte("version: 1\nproviders: [codex]\nstacks: [backend]\n"))
+	if err != nil {
+		t.Fatalf("Failed to parse config: %v", err)
+	}
+
+	if !config.GenerateBase {
+		t.Error("Expected base file to default to yes")
+	}
+	if config.OutputDir != wizard.ExpandPath(wizard.DefaultOutputDir) {
+		t.Errorf("Expected default output dir, got %q", config.OutputDir)
+	}
+	if config.ClaudeCodeMode != wizard.ClaudeCodeModeRules {
+		t.Errorf("Expected rules mode by default, got %q", config.ClaudeCodeMode)
+	}
+	if config.SyncToGitHub {
+		t.Error("Expected sync to be disabled by default")
+	}
+}
+
+func TestParseRejectsUnsupportedVersions(t *testing.T) {
+	for _, data := range []string{
+		"providers: [codex]\n",
+		"version: 99\nproviders: [codex]\n",
+	} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("Expected an error for %q", data)
+		}
+	}
+}
+
+func TestStateRoundTrip(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	state, err := LoadState(tmpDir)
+	if err != nil {
+		t.Fatalf("Expected no error for a missing state file, got %v", err)
+	}
+	if state != nil {
+		t.Fatalf("Expected no state before the first run, got %+v", state)
+	}
+
+	config := &wizard.Config{
+		Providers:      []string{"codex"},
+		TechStacks:     []string{"backend"},
+		GenerateBase:   true,
+		OutputDir:      tmpDir,
+		ClaudeCodeMode: wizard.ClaudeCodeModeRules,
+	}
+	if err := SaveState(config); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+
+	state, err = LoadState(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	if !reflect.DeepEqual(config, state) {
+		t.Errorf("Loaded state does not match saved config:\n got: %+v\nwant: %+v", state, config)
+	}
+}
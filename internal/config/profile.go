@@ -0,0 +1,253 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/agentspack/agentspack/internal/wizard"
+	"gopkg.in/yaml.v3"
+)
+
+// RepoProfilesDir is where repo-level profiles are read from, relative to the working directory
+var RepoProfilesDir = filepath.Join(".agentspack", "profiles")
+
+// Profile is a named preset that expands into a full configuration
+type Profile struct {
+	Name        string
+	Description string
+	Path        string // File the profile was read from
+	Extends     []string
+
+	file File
+}
+
+// profileFile is the on-disk representation of a profile. It accepts every
+// config file field plus profile metadata.
+type profileFile struct {
+	File        `yaml:",inline"`
+	Description string     `yaml:"description,omitempty"`
+	Extends     stringList `yaml:"extends,omitempty"`
+}
+
+// stringList decodes either a single YAML string or a list of strings
+type stringList []string
+
+func (s *stringList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*s = stringList{node.Value}
+		return nil
+	}
+	var list []string
+	if err := node.Decode(&list); err != nil {
+		return err
+	}
+	*s = list
+	return nil
+}
+
+// UserProfilesDir returns the directory user-level profiles are read from
+// ($XDG_CONFIG_HOME/agentspack/profiles, or ~/.config/agentspack/profiles)
+func UserProfilesDir() string {
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, "agentspack", "profiles")
+	}
+	return wizard.ExpandPath(filepath.Join("~", ".config", "agentspack", "profiles"))
+}
+
+// ListProfiles returns all available profiles sorted by name.
+// Repo-level profiles take precedence over user-level profiles with the same name.
+func ListProfiles() ([]Profile, error) {
+	byName := make(map[string]Profile)
+
+	// Read user-level first so repo-level definitions override them
+	for _, dir := range []string{UserProfilesDir(), RepoProfilesDir} {
+		profiles, err := readProfilesDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, profile := range profiles {
+			byName[profile.Name] = profile
+		}
+	}
+
+	result := make([]Profile, 0, len(byName))
+	for _, profile := range byName {
+		result = append(result, profile)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+// LoadProfile resolves a profile by name, including everything it extends,
+// and converts it into a wizard configuration
+func LoadProfile(name string) (*wizard.Config, error) {
+	profiles, err := ListProfiles()
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]Profile, len(profiles))
+	for _, profile := range profiles {
+		byName[profile.Name] = profile
+	}
+
+	file, err := resolveProfile(byName, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	return file.toConfig(), nil
+}
+
+// resolveProfile flattens a profile and its parents into a single file.
+// Parents are applied in order, then the profile itself on top.
+func resolveProfile(byName map[string]Profile, name string, chain []string) (*File, error) {
+	for _, seen := range chain {
+		if seen == name {
+			return nil, fmt.Errorf("profile %q extends itself (%s)", name, strings.Join(append(chain, name), " -> "))
+		}
+	}
+
+	profile, ok := byName[name]
+	if !ok {
+		if len(chain) > 0 {
+			return nil, fmt.Errorf("profile %q extends unknown profile %q", chain[len(chain)-1], name)
+		}
+		return nil, fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(profileNames(byName), ", "))
+	}
+
+	resolved := &File{}
+	for _, parent := range profile.Extends {
+		parentFile, err := resolveProfile(byName, parent, append(chain, name))
+		if err != nil {
+			return nil, err
+		}
+		resolved.overlay(parentFile)
+	}
+	resolved.overlay(&profile.file)
+	return resolved, nil
+}
+
+// readProfilesDir reads all *.yaml and *.yml profiles in a directory.
+// A missing directory is not an error.
+func readProfilesDir(dir string) ([]Profile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var profiles []Profile
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		profile, err := readProfile(path)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles, nil
+}
+
+// readProfile reads a single profile file. The profile name is the file name
+// without its extension.
+func readProfile(path string) (Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Profile{}, err
+	}
+
+	var pf profileFile
+	if err := yaml.Unmarshal(data, &pf); err != nil {
+		return Profile{}, fmt.Errorf("invalid profile %s: %w", path, err)
+	}
+	if pf.Version > CurrentVersion {
+		return Profile{}, fmt.Errorf("invalid profile %s: unsupported version %d (this build supports up to %d)", path, pf.Version, CurrentVersion)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return Profile{
+		Name:        name,
+		Description: pf.Description,
+		Path:        path,
+		Extends:     pf.Extends,
+		file:        pf.File,
+	}, nil
+}
+
+// overlay copies every field that is set in other onto f
+func (f *File) overlay(other *File) {
+	if other.Providers != nil {
+		f.Providers = other.Providers
+	}
+	if other.TechStacks != nil {
+		f.TechStacks = other.TechStacks
+	}
+	if other.ClaudeCodeMode != "" {
+		f.ClaudeCodeMode = other.ClaudeCodeMode
+	}
+	if other.GeminiContext != "" {
+		f.GeminiContext = other.GeminiContext
+	}
+	if other.GenerateBase != nil {
+		f.GenerateBase = other.GenerateBase
+	}
+	if other.OutputDir != "" {
+		f.OutputDir = other.OutputDir
+	}
+	if other.Scope != "" {
+		f.Scope = other.Scope
+	}
+	if other.Sync != nil {
+		f.Sync = other.Sync
+	}
+	if other.ProjectDir != "" {
+		f.ProjectDir = other.ProjectDir
+	}
+	if other.InferGlobs != nil {
+		f.InferGlobs = other.InferGlobs
+	}
+	if other.Provenance != nil {
+		f.Provenance = other.Provenance
+	}
+	if other.Dedupe != nil {
+		f.Dedupe = other.Dedupe
+	}
+	if other.Agents != nil {
+		f.Agents = other.Agents
+	}
+	if other.Workflows != nil {
+		f.Workflows = other.Workflows
+	}
+	if other.Rules != nil {
+		f.Rules = other.Rules
+	}
+	for stack, globs := range other.Globs {
+		if f.Globs == nil {
+			f.Globs = make(map[string]Globs)
+		}
+		f.Globs[stack] = globs
+	}
+}
+
+// profileNames returns the sorted names of the given profiles
+func profileNames(byName map[string]Profile) []string {
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// setupProfiles points the user and repo profile directories at temp dirs
+// and writes the given profiles into them
+func setupProfiles(t *testing.T, userProfiles, repoProfiles map[string]string) {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tmpDir)
+
+	oldRepoDir := RepoProfilesDir
+	RepoProfilesDir = filepath.Join(tmpDir, "repo")
+	t.Cleanup(func() { RepoProfilesDir = oldRepoDir })
+
+	write := func(dir string, profiles map[string]string) {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+		for name, data := range profiles {
+			if err := os.WriteFile(filepath.Join(dir, name+".yaml"), []byte(data), 0644); err != nil {
+				t.Fatalf("Failed to write profile %s: %v", name, err)
+			}
+		}
+	}
+	write(UserProfilesDir(), userProfiles)
+	write(RepoProfilesDir, repoProfiles)
+}
+
+func TestLoadProfileExtends(t *testing.T) {
+	setupProfiles(t,
+		map[string]string{
+			"org": "providers: [cursor, codex]\nstacks: [backend]\nbase: false\n",
+		},
+		map[string]string{
+			"frontend": "description: Frontend team\nextends: org\nstacks: [react]\n",
+		},
+	)
+
+	config, err := LoadProfile("frontend")
+	if err != nil {
+		t.Fatalf("Failed to load profile: %v", err)
+	}
+
+	if !reflect.DeepEqual(config.Providers, []string{"cursor", "codex"}) {
+		t.Errorf("Expected providers inherited from org, got %v", config.Providers)
+	}
+	if !reflect.DeepEqual(config.TechStacks, []string{"react"}) {
+		t.Errorf("Expected stacks overridden by frontend, got %v", config.TechStacks)
+	}
+	if config.GenerateBase {
+		t.Error("Expected base file setting inherited from org")
+	}
+}
+
+func TestRepoProfilesOverrideUserProfiles(t *testing.T) {
+	setupProfiles(t,
+		map[string]string{"shared": "providers: [cursor]\n"},
+		map[string]string{"shared": "providers: [codex]\n"},
+	)
+
+	config, err := LoadProfile("shared")
+	if err != nil {
+		t.Fatalf("Failed to load profile: %v", err)
+	}
+	if !reflect.DeepEqual(config.Providers, []string{"codex"}) {
+		t.Errorf("Expected repo-level profile to win, got %v", config.Providers)
+	}
+}
+
+func TestLoadProfileDetectsCycles(t *testing.T) {
+	setupProfiles(t, nil, map[string]string{
+		"a": "extends: b\n",
+		"b": "extends: [a]\n",
+	})
+
+	if _, err := LoadProfile("a"); err == nil {
+		t.Error("Expected an error for a profile cycle")
+	}
+}
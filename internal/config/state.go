@@ -0,0 +1,36 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/agentspack/agentspack/internal/wizard"
+)
+
+// StateFile is where the last run's configuration is stored, relative to the output directory
+var StateFile = filepath.Join(".agentspack", "state.yaml")
+
+// LoadState reads the configuration of the last run into outputDir.
+// It returns nil without an error when no state has been saved yet.
+func LoadState(outputDir string) (*wizard.Config, error) {
+	path := filepath.Join(outputDir, StateFile)
+	config, err := Load(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return config, nil
+}
+
+// SaveState records a configuration as the last run into its output directory
+func SaveState(config *wizard.Config) error {
+	path := filepath.Join(config.OutputDir, StateFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	return Save(path, config)
+}
@@ -0,0 +1,253 @@
+// Package dedupe keeps a single copy of the templates several providers
+// would otherwise repeat: files refer to a canonical file that other tools
+// read too (AGENTS.md) by importing it, or are left out when their tool
+// reads it already. It also reports the copies that remain.
+package dedupe
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/agentspack/agentspack/internal/output"
+	"github.com/agentspack/agentspack/internal/provenance"
+)
+
+// minCopiedLines is how many lines of a template a file must hold to count
+// as a copy of it, so a step description quoted by a workflow orchestrator
+// doesn't
+const minCopiedLines = 2
+
+// Reference is a planned file that now relies on a canonical file instead
+// of repeating its templates
+type Reference struct {
+	Path      string   // File that refers to the canonical file
+	Canonical string   // Canonical file it refers to
+	Sources   []string // Templates it no longer repeats
+	Removed   bool     // The file was left out, since its tool reads the canonical file
+}
+
+func (r Reference) String() string {
+	if r.Removed {
+		return fmt.Sprintf("%s left out, %s holds its %s", r.Path, r.Canonical, countTemplates(len(r.Sources)))
+	}
+	return fmt.Sprintf("%s imports %s instead of repeating %s", r.Path, r.Canonical, countTemplates(len(r.Sources)))
+}
+
+// Duplicate is a set of templates copied into the same planned files
+type Duplicate struct {
+	Sources []string
+	Paths   []string
+}
+
+// Apply rewrites the plan so files declaring an Import refer to the
+// canonical file holding most of their templates, and files marked Covered
+// are left out when a canonical file their tool reads holds all their
+// templates. read holds the content of every planned file's sources, which
+// is used to find the lines copied from them. It returns what it changed.
+func Apply(plan *output.Plan, read map[string][]byte) []Reference {
+	var canonical []output.File
+	for _, f := range plan.Files() {
+		if f.Canonical {
+			canonical = append(canonical, f)
+		}
+	}
+	if len(canonical) == 0 {
+		return nil
+	}
+
+	// Tools read the canonical files they planned a part of, and those
+	// their files import
+	reads := make(map[string][]output.File)
+	for _, c := range canonical {
+		for _, provider := range c.Providers() {
+			reads[provider] = append(reads[provider], c)
+		}
+	}
+
+	var references []Reference
+	plan.Update(func(f *output.File) {
+		if f.Import == "" || f.Canonical {
+			return
+		}
+		c, held := mostHeld(*f, canonical)
+		if len(held) == 0 {
+			return
+		}
+		importFile(f, c.Path, held, read)
+		reads[f.Provider] = append(reads[f.Provider], c)
+		references = append(references, Reference{Path: f.Path, Canonical: c.Path, Sources: held})
+	})
+
+	var removed []string
+	for _, f := range plan.Files() {
+		if !f.Covered || len(f.Sources) == 0 {
+			continue
+		}
+		for _, c := range reads[f.Provider] {
+			if len(holds(c, f.Sources)) == len(f.Sources) {
+				removed = append(removed, f.Path)
+				references = append(references, Reference{Path: f.Path, Canonical: c.Path, Sources: f.Sources, Removed: true})
+				break
+			}
+		}
+	}
+	for _, p := range removed {
+		plan.Remove(p)
+	}
+	return references
+}
+
+// Find returns the templates copied into more than one planned file,
+// grouped by the files they are copied into, in planning order. Once the
+// plan has source maps, templates a file only refers to or quotes a line of
+// (like workflow orchestrators) don't count as copied into it. The parts
+// of a split file count as one file, named by its first part.
+func Find(plan *output.Plan) []Duplicate {
+	var order []string
+	paths := make(map[string][]string)
+	for _, f := range plan.Files() {
+		filePath := f.Path
+		if f.PartOf != "" {
+			filePath = f.PartOf
+		}
+		for _, source := range copiedSources(f) {
+			seen, ok := paths[source]
+			if !ok {
+				order = append(order, source)
+			}
+			if !contains(seen, filePath) {
+				paths[source] = append(seen, filePath)
+			}
+		}
+	}
+
+	var duplicates []Duplicate
+	groups := make(map[string]int)
+	for _, source := range order {
+		if len(paths[source]) < 2 {
+			continue
+		}
+		key := strings.Join(paths[source], "\x00")
+		if i, ok := groups[key]; ok {
+			duplicates[i].Sources = append(duplicates[i].Sources, source)
+			continue
+		}
+		groups[key] = len(duplicates)
+		duplicates = append(duplicates, Duplicate{Sources: []string{source}, Paths: paths[source]})
+	}
+	return duplicates
+}
+
+// contains reports whether paths holds p
+func contains(paths []string, p string) bool {
+	for _, existing := range paths {
+		if existing == p {
+			return true
+		}
+	}
+	return false
+}
+
+// copiedSources returns the templates f holds at least minCopiedLines
+// lines of text from, or all its sources when it has no source map
+func copiedSources(f output.File) []string {
+	if f.SourceMap == nil {
+		return f.Sources
+	}
+	lines := strings.Split(string(f.Content), "\n")
+	copied := make(map[string]int)
+	for _, span := range f.SourceMap {
+		for _, line := range lines[span.Start-1 : span.End] {
+			// Blank lines and separators don't make a copy
+			if len(strings.TrimSpace(line)) > 3 {
+				copied[span.Source]++
+			}
+		}
+	}
+	var sources []string
+	for _, source := range f.Sources {
+		if copied[source] >= minCopiedLines {
+			sources = append(sources, source)
+		}
+	}
+	return sources
+}
+
+// mostHeld returns the canonical file holding the most of f's templates,
+// and those templates
+func mostHeld(f output.File, canonical []output.File) (output.File, []string) {
+	var best output.File
+	var bestHeld []string
+	for _, c := range canonical {
+		if held := holds(c, f.Sources); len(held) > len(bestHeld) {
+			best, bestHeld = c, held
+		}
+	}
+	return best, bestHeld
+}
+
+// holds returns the sources the canonical file was built from too
+func holds(c output.File, sources []string) []string {
+	var held []string
+	for _, source := range sources {
+		for _, s := range c.Sources {
+			if s == source {
+				held = append(held, source)
+				break
+			}
+		}
+	}
+	return held
+}
+
+// importFile replaces the lines f copied from the held templates with a
+// line importing the canonical file
+func importFile(f *output.File, canonicalPath string, held []string, read map[string][]byte) {
+	isHeld := make(map[string]bool, len(held))
+	for _, source := range held {
+		isHeld[source] = true
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(f.Content), "\n"), "\n")
+	var kept []string
+	for _, span := range provenance.Map(f.Content, f.Sources, read) {
+		if !isHeld[span.Source] {
+			kept = append(kept, lines[span.Start-1:span.End]...)
+		}
+	}
+
+	rest := strings.TrimSpace(strings.Join(kept, "\n"))
+	content := fmt.Sprintf(f.Import, relativePath(path.Dir(f.Path), canonicalPath)) + "\n"
+	if rest != "" {
+		content += "\n" + rest + "\n"
+	}
+	f.Content = []byte(content)
+
+	var sources []string
+	for _, source := range f.Sources {
+		if !isHeld[source] {
+			sources = append(sources, source)
+		}
+	}
+	f.Sources = sources
+	f.Transform = fmt.Sprintf("%s, importing %s", f.Transform, canonicalPath)
+}
+
+// relativePath returns the slash-separated path of target relative to dir
+func relativePath(dir, target string) string {
+	rel, err := filepath.Rel(filepath.FromSlash(dir), filepath.FromSlash(target))
+	if err != nil {
+		return target
+	}
+	return filepath.ToSlash(rel)
+}
+
+// countTemplates formats a number of templates (e.g. "1 template")
+func countTemplates(n int) string {
+	if n == 1 {
+		return "1 template"
+	}
+	return fmt.Sprintf("%d templates", n)
+}
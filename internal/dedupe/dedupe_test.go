@@ -0,0 +1,131 @@
+package dedupe
+
+import (
+	"testing"
+
+	"github.com/agentspack/agentspack/internal/output"
+)
+
+var templates = map[string][]byte{
+	"system/base/base.md":        []byte("# Base\n\nShared instructions\n"),
+	"system/base/Claude.md":      []byte("# Claude\n\nClaude only\n"),
+	"system/rules/global/one.md": []byte("Validate every input\n"),
+}
+
+func testPlan() *output.Plan {
+	plan := output.NewPlan()
+	plan.Add(output.File{
+		Path:      "CLAUDE.md",
+		Content:   []byte("# Base\n\nShared instructions\n\n\n# Claude\n\nClaude only\n"),
+		Sources:   []string{"system/base/base.md", "system/base/Claude.md"},
+		Provider:  "claude-code",
+		Managed:   true,
+		Import:    "@%s",
+		Transform: "concatenated",
+	})
+	plan.Add(output.File{
+		Path:     ".claude/rules/global.md",
+		Content:  []byte("---\nalwaysApply: true\n---\n\nValidate every input\n"),
+		Sources:  []string{"system/rules/global/one.md"},
+		Provider: "claude-code",
+		Covered:  true,
+	})
+	plan.Add(output.File{
+		Path:      "AGENTS.md",
+		Content:   []byte("# Base\n\nShared instructions\n\nValidate every input\n"),
+		Sources:   []string{"system/base/base.md", "system/rules/global/one.md"},
+		Provider:  "codex",
+		Managed:   true,
+		Canonical: true,
+	})
+	plan.Add(output.File{
+		Path:     ".cursor/rules/global/RULE.md",
+		Content:  []byte("---\nalwaysApply: true\n---\n\nValidate every input\n"),
+		Sources:  []string{"system/rules/global/one.md"},
+		Provider: "cursor",
+		Covered:  true,
+	})
+	return plan
+}
+
+func TestApplyImportsCanonicalFile(t *testing.T) {
+	plan := testPlan()
+	references := Apply(plan, templates)
+
+	claude, _ := plan.Get("CLAUDE.md")
+	if got, want := string(claude.Content), "@AGENTS.md\n\n# Claude\n\nClaude only\n"; got != want {
+		t.Errorf("expected CLAUDE.md to import AGENTS.md:\n%q\ngot:\n%q", want, got)
+	}
+	if len(claude.Sources) != 1 || claude.Sources[0] != "system/base/Claude.md" {
+		t.Errorf("expected only Claude.md to remain a source, got %v", claude.Sources)
+	}
+
+	// Claude Code reads AGENTS.md through the import, Cursor doesn't read it
+	// since it planned no part of it
+	if _, ok := plan.Get(".claude/rules/global.md"); ok {
+		t.Error("expected the covered Claude Code rule to be left out")
+	}
+	if _, ok := plan.Get(".cursor/rules/global/RULE.md"); !ok {
+		t.Error("expected the Cursor rule to be kept")
+	}
+
+	if len(references) != 2 || references[0].Path != "CLAUDE.md" || !references[1].Removed {
+		t.Errorf("unexpected references %+v", references)
+	}
+}
+
+func TestApplyWithoutCanonicalFile(t *testing.T) {
+	plan := output.NewPlan()
+	plan.Add(output.File{Path: "CLAUDE.md", Content: []byte("# Base\n"), Sources: []string{"system/base/base.md"}, Provider: "claude-code", Import: "@%s"})
+
+	if references := Apply(plan, templates); len(references) != 0 {
+		t.Fatalf("expected no references, got %+v", references)
+	}
+	if f, _ := plan.Get("CLAUDE.md"); string(f.Content) != "# Base\n" {
+		t.Errorf("expected CLAUDE.md to be unchanged, got %q", f.Content)
+	}
+}
+
+func TestFindGroupsTemplatesByFiles(t *testing.T) {
+	duplicates := Find(testPlan())
+	if len(duplicates) != 2 {
+		t.Fatalf("expected 2 duplicates, got %+v", duplicates)
+	}
+	if duplicates[0].Sources[0] != "system/base/base.md" || len(duplicates[0].Paths) != 2 {
+		t.Errorf("unexpected first duplicate %+v", duplicates[0])
+	}
+	if duplicates[1].Sources[0] != "system/rules/global/one.md" || len(duplicates[1].Paths) != 3 {
+		t.Errorf("unexpected second duplicate %+v", duplicates[1])
+	}
+}
+
+func TestFindCountsSplitPartsOnce(t *testing.T) {
+	plan := output.NewPlan()
+	plan.Add(output.File{
+		Path:     ".windsurf/workflows/research.md",
+		Content:  []byte("# Research\n"),
+		Sources:  []string{"system/workflows/planning/01_research.md"},
+		Provider: "windsurf",
+	})
+	plan.Add(output.File{
+		Path:     ".windsurf/workflows/research-part-2.md",
+		Content:  []byte("## More research\n"),
+		Sources:  []string{"system/workflows/planning/01_research.md"},
+		Provider: "windsurf",
+		PartOf:   ".windsurf/workflows/research.md",
+	})
+	if duplicates := Find(plan); len(duplicates) != 0 {
+		t.Fatalf("expected the parts of one file not to be duplicates, got %+v", duplicates)
+	}
+
+	plan.Add(output.File{
+		Path:     ".claude/commands/research.md",
+		Content:  []byte("# Research\n"),
+		Sources:  []string{"system/workflows/planning/01_research.md"},
+		Provider: "claude-code",
+	})
+	duplicates := Find(plan)
+	if len(duplicates) != 1 || len(duplicates[0].Paths) != 2 || duplicates[0].Paths[0] != ".windsurf/workflows/research.md" {
+		t.Errorf("expected one duplicate naming the first part, got %+v", duplicates)
+	}
+}
@@ -0,0 +1,213 @@
+package detect
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/agentspack/agentspack/internal/stacks"
+)
+
+// MaxDepth limits how deep below the project root manifests are searched for,
+// which is enough to cover monorepo layouts like apps/web/package.json
+const MaxDepth = 3
+
+// skipDirs are directories that never contain project manifests worth reading
+var skipDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+	"dist":         true,
+	"build":        true,
+	"target":       true,
+	"__pycache__":  true,
+	".venv":        true,
+	"venv":         true,
+}
+
+// Detection is a tech stack found in a project together with the evidence for it
+type Detection struct {
+	Stack    string
+	Evidence []string // e.g. "found react@18 in package.json"
+}
+
+// jsxOption matches a "jsx" compiler option in a tsconfig file
+var jsxOption = regexp.MustCompile(`"jsx"\s*:\s*"([^"]+)"`)
+
+// signals indexes the detection signals declared by stack manifests
+type signals struct {
+	packages map[string]string // dependency -> stack
+	files    map[string]string // file name -> stack
+	what     map[string]string // file name -> description
+	jsx      []string          // stacks indicated by a React JSX tsconfig
+}
+
+// newSignals collects the detection signals of the given stacks
+func newSignals(known []stacks.Stack) signals {
+	s := signals{
+		packages: make(map[string]string),
+		files:    make(map[string]string),
+		what:     make(map[string]string),
+	}
+	for _, stack := range known {
+		for _, dep := range stack.Detect.Dependencies {
+			s.packages[dep] = stack.Name
+		}
+		for file, what := range stack.Detect.Files {
+			s.files[file] = stack.Name
+			s.what[file] = what
+		}
+		if stack.Detect.TSConfigJSX {
+			s.jsx = append(s.jsx, stack.Name)
+		}
+	}
+	return s
+}
+
+// Stacks scans the project rooted at root and returns which of the known
+// stacks it uses, sorted by name, based on the signals each stack declares
+func Stacks(root string, known []stacks.Stack) ([]Detection, error) {
+	sig := newSignals(known)
+	evidence := make(map[string][]string)
+	add := func(stack, message string) {
+		evidence[stack] = append(evidence[stack], message)
+	}
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, relErr := filepath.Rel(root, path)
+		if relErr != nil {
+			rel = path
+		}
+
+		if d.IsDir() {
+			if path == root {
+				return nil
+			}
+			if skipDirs[d.Name()] || strings.HasPrefix(d.Name(), ".") || strings.Count(rel, string(filepath.Separator)) >= MaxDepth {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		name := d.Name()
+		switch {
+		case name == "package.json":
+			return detectPackageJSON(path, rel, sig.packages, add)
+		case strings.HasPrefix(name, "tsconfig") && strings.HasSuffix(name, ".json") && len(sig.jsx) > 0:
+			return detectTSConfig(path, rel, sig.jsx, add)
+		}
+
+		if stack, ok := sig.files[name]; ok {
+			add(stack, fmt.Sprintf("found %s (%s)", rel, sig.what[name]))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var detections []Detection
+	for _, stack := range known {
+		if messages, ok := evidence[stack.Name]; ok {
+			detections = append(detections, Detection{Stack: stack.Name, Evidence: messages})
+		}
+	}
+	sort.Slice(detections, func(i, j int) bool {
+		return detections[i].Stack < detections[j].Stack
+	})
+	return detections, nil
+}
+
+// Names returns the stack names of the given detections
+func Names(detections []Detection) []string {
+	names := make([]string, len(detections))
+	for i, d := range detections {
+		names[i] = d.Stack
+	}
+	return names
+}
+
+// Describe formats detections as one "stack: evidence" line per piece of evidence
+func Describe(detections []Detection) []string {
+	var lines []string
+	for _, d := range detections {
+		for _, e := range d.Evidence {
+			lines = append(lines, fmt.Sprintf("%s: %s", d.Stack, e))
+		}
+	}
+	return lines
+}
+
+// detectPackageJSON reports stacks indicated by package.json dependencies
+func detectPackageJSON(path, rel string, packages map[string]string, add func(stack, message string)) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var pkg struct {
+		Dependencies     map[string]string `json:"dependencies"`
+		DevDependencies  map[string]string `json:"devDependencies"`
+		PeerDependencies map[string]string `json:"peerDependencies"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		// A malformed package.json shouldn't stop detection of everything else
+		return nil
+	}
+
+	deps := make(map[string]string)
+	for _, group := range []map[string]string{pkg.PeerDependencies, pkg.DevDependencies, pkg.Dependencies} {
+		for name, version := range group {
+			deps[name] = version
+		}
+	}
+
+	names := make([]string, 0, len(deps))
+	for name := range deps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if stack, ok := packages[name]; ok {
+			add(stack, fmt.Sprintf("found %s in %s", formatDependency(name, deps[name]), rel))
+		}
+	}
+	return nil
+}
+
+// detectTSConfig reports the given stacks when a tsconfig compiles JSX for
+// React ("react", "react-jsx", "react-jsxdev" or "react-native")
+func detectTSConfig(path, rel string, jsxStacks []string, add func(stack, message string)) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	matches := jsxOption.FindSubmatch(data)
+	if matches != nil && strings.HasPrefix(string(matches[1]), "react") {
+		for _, stack := range jsxStacks {
+			add(stack, fmt.Sprintf("found \"jsx\": %q in %s", string(matches[1]), rel))
+		}
+	}
+	return nil
+}
+
+// formatDependency renders a dependency as name@major (e.g. react@18),
+// falling back to the bare name when the version isn't a plain semver range
+func formatDependency(name, version string) string {
+	version = strings.TrimLeft(version, "^~>=< v")
+	major, _, _ := strings.Cut(version, ".")
+	if major == "" || strings.Trim(major, "0123456789") != "" {
+		return name
+	}
+	return name + "@" + major
+}
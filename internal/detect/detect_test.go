@@ -0,0 +1,126 @@
+package detect
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/agentspack/agentspack/internal/content"
+	"github.com/agentspack/agentspack/internal/stacks"
+)
+
+// writeFiles creates the given files (relative path -> content) under root
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for rel, data := range files {
+		path := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir for %s: %v", rel, err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", rel, err)
+		}
+	}
+}
+
+// loadStacks returns the named stacks as declared by the repo's stack manifests
+func loadStacks(t *testing.T, names ...string) []stacks.Stack {
+	t.Helper()
+	registry, err := stacks.Load(content.NewLocalFS(filepath.Join("..", "..")))
+	if err != nil {
+		t.Fatalf("Failed to load stacks: %v", err)
+	}
+	var result []stacks.Stack
+	for _, name := range names {
+		stack, ok := registry.Get(name)
+		if !ok {
+			t.Fatalf("Stack %q is not declared", name)
+		}
+		result = append(result, stack)
+	}
+	return result
+}
+
+func TestStacksInMonorepo(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"apps/web/package.json":             `{"dependencies": {"react": "^18.2.0"}}`,
+		"services/api/pyproject.toml":       "[project]\nname = \"api\"\n",
+		"node_modules/express/package.json": `{"dependencies": {"express": "4.0.0"}}`,
+		"apps/web/node_modules/x/go.mod":    "module x\n",
+	})
+
+	detections, err := Stacks(root, loadStacks(t, "backend", "react"))
+	if err != nil {
+		t.Fatalf("Detection failed: %v", err)
+	}
+
+	want := []Detection{
+		{Stack: "backend", Evidence: []string{"found services/api/pyproject.toml (Python project)"}},
+		{Stack: "react", Evidence: []string{"found react@18 in apps/web/package.json"}},
+	}
+	if !reflect.DeepEqual(detections, want) {
+		t.Errorf("Unexpected detections:\n got: %+v\nwant: %+v", detections, want)
+	}
+}
+
+func TestStacksOnlyReportsKnownStacks(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"go.mod": "module x\n"})
+
+	detections, err := Stacks(root, loadStacks(t, "react"))
+	if err != nil {
+		t.Fatalf("Detection failed: %v", err)
+	}
+	if len(detections) != 0 {
+		t.Errorf("Expected no detections, got %+v", detections)
+	}
+}
+
+func TestInferGlobsAnchorsAtPackageRoots(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod":                             "module x\n",
+		"apps/web/package.json":              "{}",
+		"apps/web/src/components/Button.tsx": "",
+		"apps/web/src/lib/format.ts":         "",
+		"services/api/pyproject.toml":        "",
+		"services/api/app/main.py":           "",
+		"services/worker/index.ts":           "",
+		"internal/server/server.go":          "",
+		"node_modules/pkg/index.tsx":         "",
+	})
+
+	globs, err := InferGlobs(root, loadStacks(t, "backend", "react"))
+	if err != nil {
+		t.Fatalf("Glob inference failed: %v", err)
+	}
+
+	want := map[string][]string{
+		"backend": {"internal/**/*.go", "services/**/*.ts", "services/api/**/*.py"},
+		"react":   {"apps/web/src/**/*.tsx"},
+	}
+	if !reflect.DeepEqual(globs, want) {
+		t.Errorf("Unexpected globs:\n got: %v\nwant: %v", globs, want)
+	}
+}
+
+func TestInferGlobsCollapsesToRepoWideGlob(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"main.go":       "",
+		"cmd/root.go":   "",
+		"pkg/util/x.go": "",
+	})
+
+	globs, err := InferGlobs(root, loadStacks(t, "backend", "react"))
+	if err != nil {
+		t.Fatalf("Glob inference failed: %v", err)
+	}
+
+	want := map[string][]string{"backend": {"**/*.go"}}
+	if !reflect.DeepEqual(globs, want) {
+		t.Errorf("Unexpected globs:\n got: %v\nwant: %v", globs, want)
+	}
+}
@@ -0,0 +1,153 @@
+package detect
+
+import (
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/agentspack/agentspack/internal/stacks"
+)
+
+// rootManifests mark the root of a package inside a (mono)repo
+var rootManifests = []string{
+	"package.json", "go.mod", "pyproject.toml", "requirements.txt", "Pipfile",
+	"Cargo.toml", "wrangler.toml", "wrangler.jsonc", "composer.json", "Gemfile",
+	"pom.xml", "build.gradle",
+}
+
+// InferGlobs walks the project rooted at root and returns, for each of the
+// given stacks, globs that match where the stack's files actually live
+// (e.g. "apps/web/src/**/*.tsx"). Stacks without any matching files are
+// left out of the result.
+func InferGlobs(root string, known []stacks.Stack) (map[string][]string, error) {
+	manifestDirs := make(map[string]bool)
+	var files []string
+
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != root && (skipDirs[d.Name()] || strings.HasPrefix(d.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		for _, manifest := range rootManifests {
+			if d.Name() == manifest {
+				manifestDirs[path.Dir(rel)] = true
+			}
+		}
+		files = append(files, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Group file extensions by the source root they live in
+	rootExts := make(map[string]map[string]bool)
+	for _, file := range files {
+		ext := path.Ext(file)
+		if ext == "" {
+			continue
+		}
+		sourceRoot := findSourceRoot(file, manifestDirs)
+		if rootExts[sourceRoot] == nil {
+			rootExts[sourceRoot] = make(map[string]bool)
+		}
+		rootExts[sourceRoot][ext] = true
+	}
+
+	result := make(map[string][]string)
+	for _, stack := range known {
+		var globs []string
+		for sourceRoot, exts := range rootExts {
+			for _, ext := range stack.Infer.Extensions {
+				if !exts[ext] || hasAny(exts, stack.Infer.Ambiguous[ext]) {
+					continue
+				}
+				globs = append(globs, rootGlob(sourceRoot, ext))
+			}
+		}
+		if len(globs) > 0 {
+			result[stack.Name] = collapseGlobs(globs)
+		}
+	}
+	return result, nil
+}
+
+// findSourceRoot returns the directory a file's globs should be anchored at:
+// the nearest enclosing package (a directory with a manifest, other than the
+// repo root) or otherwise the file's top-level directory. When that directory
+// has a src/ folder containing the file, the glob is tightened to src/.
+func findSourceRoot(file string, manifestDirs map[string]bool) string {
+	dir := path.Dir(file)
+	if dir == "." {
+		return ""
+	}
+
+	sourceRoot := ""
+	for d := dir; d != "."; d = path.Dir(d) {
+		if manifestDirs[d] {
+			sourceRoot = d
+			break
+		}
+	}
+	if sourceRoot == "" {
+		sourceRoot = strings.SplitN(dir, "/", 2)[0]
+	}
+
+	if src := path.Join(sourceRoot, "src"); dir == src || strings.HasPrefix(dir, src+"/") {
+		return src
+	}
+	return sourceRoot
+}
+
+// rootGlob builds a glob matching all files with ext below sourceRoot
+func rootGlob(sourceRoot, ext string) string {
+	if sourceRoot == "" {
+		return "**/*" + ext
+	}
+	return sourceRoot + "/**/*" + ext
+}
+
+// collapseGlobs sorts globs and drops those already covered by a
+// repo-wide glob for the same extension
+func collapseGlobs(globs []string) []string {
+	repoWide := make(map[string]bool)
+	for _, glob := range globs {
+		if strings.HasPrefix(glob, "**/") {
+			repoWide[path.Ext(glob)] = true
+		}
+	}
+
+	var result []string
+	for _, glob := range globs {
+		if repoWide[path.Ext(glob)] && !strings.HasPrefix(glob, "**/") {
+			continue
+		}
+		result = append(result, glob)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// hasAny checks if any of the extensions are in the set
+func hasAny(set map[string]bool, exts []string) bool {
+	for _, ext := range exts {
+		if set[ext] {
+			return true
+		}
+	}
+	return false
+}
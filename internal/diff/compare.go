@@ -0,0 +1,116 @@
+package diff
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/agentspack/agentspack/internal/output"
+)
+
+// Modified is a planned file whose content differs from the file on disk
+type Modified struct {
+	File    output.File
+	Current []byte // Content currently on disk
+	Desired []byte // Content the file would have after generating
+}
+
+// Result is the difference between an output plan and an output directory
+type Result struct {
+	Added     []output.File // Planned files that don't exist on disk
+	Modified  []Modified    // Planned files whose content differs
+	Orphaned  []string      // Files in owned directories that the plan no longer produces
+	Unchanged []output.File // Planned files identical to the file on disk
+}
+
+// HasChanges reports whether the output directory differs from the plan
+func (r *Result) HasChanges() bool {
+	return len(r.Added) > 0 || len(r.Modified) > 0 || len(r.Orphaned) > 0
+}
+
+// Compare checks every planned file against outputDir. Files below one of
+// ownedDirs or listed in ownedFiles (both relative to outputDir) that the
+// plan doesn't produce are orphaned.
+func Compare(plan *output.Plan, outputDir string, ownedDirs, ownedFiles []string) (*Result, error) {
+	result := &Result{}
+	for _, file := range plan.Files() {
+		current, err := os.ReadFile(filepath.Join(outputDir, filepath.FromSlash(file.Path)))
+		if errors.Is(err, fs.ErrNotExist) {
+			result.Added = append(result.Added, file)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file.Path, err)
+		}
+
+		desired, err := output.Render(file, current)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Equal(current, desired) {
+			result.Unchanged = append(result.Unchanged, file)
+			continue
+		}
+		result.Modified = append(result.Modified, Modified{File: file, Current: current, Desired: desired})
+	}
+
+	orphaned, err := findOrphans(plan, outputDir, ownedDirs, ownedFiles)
+	if err != nil {
+		return nil, err
+	}
+	result.Orphaned = orphaned
+
+	sort.Slice(result.Added, func(i, j int) bool { return result.Added[i].Path < result.Added[j].Path })
+	sort.Slice(result.Modified, func(i, j int) bool { return result.Modified[i].File.Path < result.Modified[j].File.Path })
+	return result, nil
+}
+
+// findOrphans lists the owned files that exist on disk but aren't in the plan
+func findOrphans(plan *output.Plan, outputDir string, ownedDirs, ownedFiles []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var orphaned []string
+	for _, file := range ownedFiles {
+		if _, ok := plan.Get(file); ok || seen[file] {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(outputDir, filepath.FromSlash(file))); err == nil {
+			seen[file] = true
+			orphaned = append(orphaned, file)
+		}
+	}
+	for _, dir := range ownedDirs {
+		root := filepath.Join(outputDir, filepath.FromSlash(dir))
+		err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					return nil
+				}
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+
+			rel, err := filepath.Rel(outputDir, p)
+			if err != nil {
+				return err
+			}
+			rel = filepath.ToSlash(rel)
+			if _, ok := plan.Get(rel); !ok && !seen[rel] {
+				seen[rel] = true
+				orphaned = append(orphaned, rel)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", path.Clean(dir), err)
+		}
+	}
+	sort.Strings(orphaned)
+	return orphaned, nil
+}
@@ -0,0 +1,99 @@
+package diff
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/agentspack/agentspack/internal/output"
+)
+
+func TestUnified(t *testing.T) {
+	oldContent := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n"
+	newContent := "a\nb\nc\nD\ne\nf\ng\nh\ni\nj\nk\n"
+
+	expected := strings.Join([]string{
+		"--- a/file.md",
+		"+++ b/file.md",
+		"@@ -1,10 +1,11 @@",
+		" a",
+		" b",
+		" c",
+		"-d",
+		"+D",
+		" e",
+		" f",
+		" g",
+		" h",
+		" i",
+		" j",
+		"+k",
+		"",
+	}, "\n")
+	if got := Unified("a/file.md", "b/file.md", oldContent, newContent); got != expected {
+		t.Fatalf("unexpected diff:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestUnifiedSeparateHunksAndMissingNewline(t *testing.T) {
+	oldContent := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12"
+	newContent := "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+
+	got := Unified("a/f", "b/f", oldContent, newContent)
+	if strings.Count(got, "@@ -") != 2 {
+		t.Fatalf("expected two hunks, got:\n%s", got)
+	}
+	if !strings.Contains(got, "@@ -1,4 +1,4 @@\n-1\n+one\n") {
+		t.Fatalf("missing first hunk in:\n%s", got)
+	}
+	if !strings.Contains(got, "-12\n\\ No newline at end of file\n+12\n") {
+		t.Fatalf("missing newline marker in:\n%s", got)
+	}
+	if Unified("a/f", "b/f", oldContent, oldContent) != "" {
+		t.Fatal("expected no diff for equal content")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "AGENTS.md", "old\n")
+	writeFile(t, dir, ".codex/skills/backend-guidelines/SKILL.md", "same\n")
+	writeFile(t, dir, ".codex/skills/old-skill/SKILL.md", "stale\n")
+	writeFile(t, dir, "README.md", "not owned\n")
+	writeFile(t, dir, "CLAUDE.md", "generated earlier\n")
+
+	plan := output.NewPlan()
+	plan.Add(output.File{Path: "AGENTS.md", Content: []byte("new\n")})
+	plan.Add(output.File{Path: ".codex/skills/backend-guidelines/SKILL.md", Content: []byte("same\n")})
+	plan.Add(output.File{Path: ".codex/skills/react-guidelines/SKILL.md", Content: []byte("added\n")})
+
+	result, err := Compare(plan, dir, []string{".codex/skills", ".cursor/rules"}, []string{"CLAUDE.md", "GEMINI.md"})
+	if err != nil {
+		t.Fatalf("Compare failed: %v", err)
+	}
+
+	if len(result.Modified) != 1 || result.Modified[0].File.Path != "AGENTS.md" || string(result.Modified[0].Current) != "old\n" {
+		t.Fatalf("unexpected modified files: %+v", result.Modified)
+	}
+	if len(result.Added) != 1 || result.Added[0].Path != ".codex/skills/react-guidelines/SKILL.md" {
+		t.Fatalf("unexpected added files: %+v", result.Added)
+	}
+	if len(result.Orphaned) != 2 || result.Orphaned[0] != ".codex/skills/old-skill/SKILL.md" || result.Orphaned[1] != "CLAUDE.md" {
+		t.Fatalf("unexpected orphaned files: %v", result.Orphaned)
+	}
+	if len(result.Unchanged) != 1 || !result.HasChanges() {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
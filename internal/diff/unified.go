@@ -0,0 +1,197 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ContextLines is the number of unchanged lines shown around each change
+const ContextLines = 3
+
+// opKind is the kind of a line in an edit script
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+// op is one line of an edit script, indexing into the old (a) or new (b) lines
+type op struct {
+	kind opKind
+	a, b int
+}
+
+// Unified returns a unified diff turning oldContent into newContent, with
+// oldName and newName in the --- and +++ headers. It returns an empty
+// string when the contents are equal.
+func Unified(oldName, newName, oldContent, newContent string) string {
+	if oldContent == newContent {
+		return ""
+	}
+
+	a, b := splitLines(oldContent), splitLines(newContent)
+	ops := editScript(a, b)
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+	for _, h := range hunks(ops) {
+		writeHunk(&out, ops[h[0]:h[1]], a, b)
+	}
+	return out.String()
+}
+
+// splitLines splits content into lines, keeping each line's newline
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// editScript computes a shortest edit script from a to b using the longest
+// common subsequence of the lines between their common prefix and suffix
+func editScript(a, b []string) []op {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]op, 0, len(a)+len(b))
+	for i := 0; i < prefix; i++ {
+		ops = append(ops, op{opEqual, i, i})
+	}
+
+	// lcs[i][j] is the LCS length of the middle of a from i and b from j
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	n, m := len(midA), len(midB)
+	lcs := make([][]int32, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if midA[i] == midB[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && midA[i] == midB[j]:
+			ops = append(ops, op{opEqual, prefix + i, prefix + j})
+			i++
+			j++
+		case i < n && (j == m || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, op{opDelete, prefix + i, prefix + j})
+			i++
+		default:
+			ops = append(ops, op{opInsert, prefix + i, prefix + j})
+			j++
+		}
+	}
+
+	for k := 0; k < suffix; k++ {
+		ops = append(ops, op{opEqual, len(a) - suffix + k, len(b) - suffix + k})
+	}
+	return ops
+}
+
+// hunks groups the changes of an edit script with their surrounding context,
+// returning [start, end) ranges of ops. Changes separated by no more than
+// twice the context share a hunk.
+func hunks(ops []op) [][2]int {
+	var ranges [][2]int
+	for i := 0; i < len(ops); i++ {
+		if ops[i].kind == opEqual {
+			continue
+		}
+
+		start := max(0, i-ContextLines)
+		end := i + 1
+		for end < len(ops) {
+			if ops[end].kind != opEqual {
+				end++
+				continue
+			}
+			// Look ahead for another change within reach of this hunk
+			next := end
+			for next < len(ops) && ops[next].kind == opEqual && next-end < 2*ContextLines {
+				next++
+			}
+			if next < len(ops) && ops[next].kind != opEqual {
+				end = next
+				continue
+			}
+			break
+		}
+		end = min(len(ops), end+ContextLines)
+
+		ranges = append(ranges, [2]int{start, end})
+		i = end - 1
+	}
+	return ranges
+}
+
+// writeHunk writes a hunk header followed by its lines
+func writeHunk(out *strings.Builder, ops []op, a, b []string) {
+	oldStart, newStart := ops[0].a+1, ops[0].b+1
+	oldCount, newCount := 0, 0
+	for _, o := range ops {
+		if o.kind != opInsert {
+			oldCount++
+		}
+		if o.kind != opDelete {
+			newCount++
+		}
+	}
+	// An empty range names the line before it
+	if oldCount == 0 {
+		oldStart--
+	}
+	if newCount == 0 {
+		newStart--
+	}
+
+	fmt.Fprintf(out, "@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+	for _, o := range ops {
+		switch o.kind {
+		case opEqual:
+			writeLine(out, " ", a[o.a])
+		case opDelete:
+			writeLine(out, "-", a[o.a])
+		case opInsert:
+			writeLine(out, "+", b[o.b])
+		}
+	}
+}
+
+// hunkRange formats the start and length of a hunk side, omitting a length of one
+func hunkRange(start, count int) string {
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// writeLine writes a diff line, marking a missing final newline the way git does
+func writeLine(out *strings.Builder, prefix, line string) {
+	out.WriteString(prefix)
+	out.WriteString(line)
+	if !strings.HasSuffix(line, "\n") {
+		out.WriteString("\n\\ No newline at end of file\n")
+	}
+}
@@ -0,0 +1,173 @@
+// Package export writes a generation plan as an archive or a git patch
+// instead of writing the files to the output directory, for machines
+// where agentspack can't run.
+package export
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/agentspack/agentspack/internal/diff"
+	"github.com/agentspack/agentspack/internal/manifest"
+	"github.com/agentspack/agentspack/internal/output"
+)
+
+// Output formats
+const (
+	FormatDir   = "dir"    // Write the files to the output directory (the default)
+	FormatZip   = "zip"    // Zip archive of the rendered tree and the manifest
+	FormatTarGz = "tar.gz" // Gzipped tar archive of the rendered tree and the manifest
+	FormatPatch = "patch"  // git apply-able patch against the output directory
+)
+
+// Formats lists the supported output formats
+var Formats = []string{FormatDir, FormatZip, FormatTarGz, FormatPatch}
+
+// Extension returns the file extension for a format (e.g. ".tar.gz")
+func Extension(format string) string {
+	return "." + format
+}
+
+// entry is a file in an archive
+type entry struct {
+	path    string
+	content []byte
+	mode    os.FileMode
+}
+
+// archiveEntries returns the rendered files of a plan followed by the
+// manifest. Managed blocks are rendered on their own, with their markers,
+// since there is no existing file to merge them into.
+func archiveEntries(plan *output.Plan, m *manifest.Manifest) ([]entry, error) {
+	var entries []entry
+	for _, f := range plan.Files() {
+		content, err := output.Render(f, nil)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry{path: f.Path, content: content, mode: f.Mode})
+	}
+
+	data, err := m.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	entries = append(entries, entry{path: filepath.ToSlash(manifest.FileName), content: data, mode: output.DefaultMode})
+	return entries, nil
+}
+
+// Zip writes the plan and its manifest to w as a zip archive
+func Zip(w io.Writer, plan *output.Plan, m *manifest.Manifest) error {
+	entries, err := archiveEntries(plan, m)
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	now := time.Now()
+	for _, e := range entries {
+		header := &zip.FileHeader{Name: e.path, Method: zip.Deflate, Modified: now}
+		header.SetMode(e.mode)
+		fw, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(e.content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// TarGz writes the plan and its manifest to w as a gzipped tar archive
+func TarGz(w io.Writer, plan *output.Plan, m *manifest.Manifest) error {
+	entries, err := archiveEntries(plan, m)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	now := time.Now()
+	for _, e := range entries {
+		header := &tar.Header{
+			Name:    e.path,
+			Mode:    int64(e.mode.Perm()),
+			Size:    int64(len(e.content)),
+			ModTime: now,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := tw.Write(e.content); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// Patch writes a patch to w that turns targetDir into the planned output,
+// including its manifest, and can be applied with `git apply` from inside
+// targetDir. It returns the number of files the patch changes.
+func Patch(w io.Writer, plan *output.Plan, m *manifest.Manifest, targetDir string) (int, error) {
+	files := append([]output.File{}, plan.Files()...)
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+
+	data, err := m.Marshal()
+	if err != nil {
+		return 0, err
+	}
+	files = append(files, output.File{Path: filepath.ToSlash(manifest.FileName), Content: data, Mode: output.DefaultMode})
+
+	changed := 0
+	for _, f := range files {
+		current, err := os.ReadFile(filepath.Join(targetDir, filepath.FromSlash(f.Path)))
+		exists := err == nil
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return 0, err
+		}
+
+		desired, err := output.Render(f, current)
+		if err != nil {
+			return 0, err
+		}
+		if exists && bytes.Equal(current, desired) {
+			continue
+		}
+		if err := writeFilePatch(w, f, current, desired, exists); err != nil {
+			return 0, err
+		}
+		changed++
+	}
+	return changed, nil
+}
+
+// writeFilePatch writes the git diff of a single file
+func writeFilePatch(w io.Writer, f output.File, current, desired []byte, exists bool) error {
+	oldName := path.Join("a", f.Path)
+	header := fmt.Sprintf("diff --git a/%s b/%s\n", f.Path, f.Path)
+	if !exists {
+		oldName = "/dev/null"
+		header += fmt.Sprintf("new file mode 100%o\n", f.Mode.Perm())
+	}
+
+	body := diff.Unified(oldName, path.Join("b", f.Path), string(current), string(desired))
+	_, err := io.WriteString(w, header+body)
+	return err
+}
@@ -0,0 +1,97 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/agentspack/agentspack/internal/manifest"
+	"github.com/agentspack/agentspack/internal/output"
+)
+
+func testPlan() *output.Plan {
+	plan := output.NewPlan()
+	plan.Add(output.File{Path: "AGENTS.md", Content: []byte("agents\n"), Provider: "codex", Managed: true})
+	plan.Add(output.File{Path: ".codex/skills/api/SKILL.md", Content: []byte("one\ntwo\n"), Provider: "codex"})
+	return plan
+}
+
+func TestZipContainsTreeAndManifest(t *testing.T) {
+	plan := testPlan()
+	m, err := manifest.New(plan, "")
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := Zip(&buf, plan, m); err != nil {
+		t.Fatalf("Zip failed: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("invalid zip: %v", err)
+	}
+	contents := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		contents[f.Name] = string(data)
+	}
+
+	if len(contents) != 3 || contents[".codex/skills/api/SKILL.md"] != "one\ntwo\n" {
+		t.Fatalf("unexpected archive contents %v", contents)
+	}
+	if !strings.HasPrefix(contents["AGENTS.md"], output.BlockBegin) {
+		t.Fatalf("expected the managed block with its markers, got %q", contents["AGENTS.md"])
+	}
+	if !strings.Contains(contents[".agentspack/manifest.json"], `"path": "AGENTS.md"`) {
+		t.Fatalf("expected the manifest in the archive, got %q", contents[".agentspack/manifest.json"])
+	}
+}
+
+func TestPatchAgainstTargetDirectory(t *testing.T) {
+	dir := t.TempDir()
+	skill := filepath.Join(dir, ".codex", "skills", "api", "SKILL.md")
+	if err := os.MkdirAll(filepath.Dir(skill), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(skill, []byte("one\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "AGENTS.md"), []byte("# Notes\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	plan := testPlan()
+	m, err := manifest.New(plan, dir)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	var buf bytes.Buffer
+	changed, err := Patch(&buf, plan, m, dir)
+	if err != nil {
+		t.Fatalf("Patch failed: %v", err)
+	}
+	if changed != 3 {
+		t.Fatalf("expected 3 changed files, got %d:\n%s", changed, buf.String())
+	}
+
+	patch := buf.String()
+	for _, want := range []string{
+		"diff --git a/.codex/skills/api/SKILL.md b/.codex/skills/api/SKILL.md\n--- a/.codex/skills/api/SKILL.md\n+++ b/.codex/skills/api/SKILL.md\n@@ -1 +1,2 @@\n one\n+two\n",
+		"diff --git a/AGENTS.md b/AGENTS.md\n--- a/AGENTS.md\n+++ b/AGENTS.md\n@@ -1 +1,5 @@\n # Notes\n+\n+" + output.BlockBegin + "\n",
+		"diff --git a/.agentspack/manifest.json b/.agentspack/manifest.json\nnew file mode 100644\n--- /dev/null\n+++ b/.agentspack/manifest.json\n",
+	} {
+		if !strings.Contains(patch, want) {
+			t.Errorf("expected patch to contain:\n%s\ngot:\n%s", want, patch)
+		}
+	}
+}
@@ -1,12 +1,23 @@
 package generator
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/agentspack/agentspack/internal/content"
+	"github.com/agentspack/agentspack/internal/dedupe"
+	"github.com/agentspack/agentspack/internal/detect"
+	"github.com/agentspack/agentspack/internal/export"
+	"github.com/agentspack/agentspack/internal/manifest"
+	"github.com/agentspack/agentspack/internal/output"
+	"github.com/agentspack/agentspack/internal/provenance"
 	"github.com/agentspack/agentspack/internal/providers"
+	"github.com/agentspack/agentspack/internal/stacks"
+	"github.com/agentspack/agentspack/internal/version"
 	"github.com/agentspack/agentspack/internal/wizard"
 )
 
@@ -15,6 +26,14 @@ type Generator struct {
 	config *wizard.Config
 	fs     content.FileSystem
 	fsType string
+
+	// Prune removes the files the previous run generated that this run no longer produces
+	Prune bool
+	// ConfirmRemove is asked before pruning a file that was edited by hand.
+	// Edited files are kept when it is nil.
+	ConfirmRemove func(path string) bool
+
+	references []dedupe.Reference // What dedupe changed in the last plan
 }
 
 // New creates a new Generator instance
@@ -28,13 +47,9 @@ func New(config *wizard.Config, localSystemDir string) *Generator {
 	}
 }
 
-// Run executes the generation process for all selected providers
+// Run plans the files for all selected providers and writes them to the output directory
 func (g *Generator) Run() error {
-	// Create output directory
 	outputDir := g.config.OutputDir
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
-	}
 
 	// Get absolute path for output
 	absOutputDir, err := filepath.Abs(outputDir)
@@ -45,7 +60,289 @@ func (g *Generator) Run() error {
 	fmt.Printf("\nUsing %s templates\n", g.fsType)
 	fmt.Printf("Generating files to: %s\n\n", absOutputDir)
 
-	// Process each selected provider
+	plan, err := g.Plan()
+	if err != nil {
+		return err
+	}
+
+	// Read the previous run's manifest before overwriting anything
+	previous, err := manifest.Load(outputDir)
+	if err != nil {
+		return err
+	}
+
+	// Create output directory
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// Write each provider's files. The home directory holds the user's own
+	// files too, so a user-level install never overwrites a file agentspack
+	// didn't generate or one that was edited since.
+	var skipped []string
+	for _, providerName := range g.config.Providers {
+		files := plan.ByProvider(providerName)
+		if len(files) == 0 {
+			continue
+		}
+
+		fmt.Printf("Generating for %s...\n", providerName)
+		for _, file := range files {
+			if g.config.IsUserScope() {
+				reason, err := previous.Protected(outputDir, file)
+				if err != nil {
+					return fmt.Errorf("failed to check %s: %w", file.Path, err)
+				}
+				if reason != "" {
+					fmt.Printf("  Skipped: %s (%s)\n", file.Path, reason)
+					skipped = append(skipped, file.Path)
+					continue
+				}
+			}
+
+			outputPath, err := output.Write(outputDir, file)
+			if err != nil {
+				return fmt.Errorf("failed to generate for %s: %w", providerName, err)
+			}
+			if shared := file.Providers()[1:]; len(shared) > 0 {
+				fmt.Printf("  Created: %s (shared with %s)\n", outputPath, strings.Join(shared, ", "))
+				continue
+			}
+			fmt.Printf("  Created: %s\n", outputPath)
+		}
+		fmt.Println()
+	}
+
+	if len(skipped) > 0 {
+		fmt.Printf("%d existing files were left untouched; move them aside and run again to install agentspack's versions\n\n", len(skipped))
+	}
+
+	if err := g.writeManifest(plan, previous, skipped); err != nil {
+		return err
+	}
+
+	g.printDuplication(plan)
+	printWarnings(plan)
+	fmt.Println("Generation complete!")
+	return nil
+}
+
+// writeManifest records the generated files in the output directory's
+// manifest. Files of the previous run that are no longer generated are
+// removed with Prune, and otherwise stay in the manifest for a later clean.
+func (g *Generator) writeManifest(plan *output.Plan, previous *manifest.Manifest, skipped []string) error {
+	stale, err := g.staleEntries(plan, previous)
+	if err != nil {
+		return err
+	}
+
+	if len(stale) > 0 && g.Prune {
+		fmt.Println("Pruning files that are no longer generated...")
+		kept, err := manifest.Prune(g.config.OutputDir, stale, g.ConfirmRemove, false)
+		if err != nil {
+			return err
+		}
+		stale = kept
+		fmt.Println()
+	} else if len(stale) > 0 {
+		fmt.Printf("%d files from the previous run are no longer generated (run 'agentspack clean' or pass --prune to remove them)\n\n", len(stale))
+	}
+
+	return g.saveManifest(plan, previous, skipped, stale)
+}
+
+// staleEntries returns the files of the previous run that the plan no
+// longer produces and that are still on disk
+func (g *Generator) staleEntries(plan *output.Plan, previous *manifest.Manifest) ([]manifest.Entry, error) {
+	var stale []manifest.Entry
+	for _, entry := range previous.Stale(plan) {
+		state, err := entry.State(g.config.OutputDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check %s: %w", entry.Path, err)
+		}
+		if state != manifest.Missing {
+			stale = append(stale, entry)
+		}
+	}
+	return stale, nil
+}
+
+// saveManifest writes the manifest for a plan. Skipped files keep their
+// previous entry, if they had one, and stale files stay listed.
+func (g *Generator) saveManifest(plan *output.Plan, previous *manifest.Manifest, skipped []string, stale []manifest.Entry) error {
+	next, err := manifest.New(plan, g.config.OutputDir)
+	if err != nil {
+		return fmt.Errorf("failed to hash the generated files: %w", err)
+	}
+	for _, path := range skipped {
+		next.Forget(path)
+		if entry, ok := previous.Get(path); ok {
+			next.Keep([]manifest.Entry{entry})
+		}
+	}
+	next.Keep(stale)
+
+	if err := next.Save(g.config.OutputDir); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+// Changes lists what Update wrote
+type Changes struct {
+	Added   []string // Files planned for the first time
+	Updated []string // Files whose content changed
+	Removed []string // Files no longer generated; left on disk for 'agentspack clean'
+	Skipped []string // Files in the user's home directory that weren't overwritten
+}
+
+// IsEmpty reports whether nothing changed
+func (c Changes) IsEmpty() bool {
+	return len(c.Added)+len(c.Updated)+len(c.Removed)+len(c.Skipped) == 0
+}
+
+// Update plans the files again and writes only those whose content differs
+// from the previous plan, or every file when previous is nil. It returns
+// the new plan, to pass to the next Update, and what changed.
+func (g *Generator) Update(previous *output.Plan) (*output.Plan, Changes, error) {
+	outputDir := g.config.OutputDir
+	var changes Changes
+
+	plan, err := g.Plan()
+	if err != nil {
+		return nil, changes, err
+	}
+
+	owned, err := manifest.Load(outputDir)
+	if err != nil {
+		return nil, changes, err
+	}
+
+	for _, file := range plan.Files() {
+		var before output.File
+		existed := false
+		if previous != nil {
+			before, existed = previous.Get(file.Path)
+		}
+		if existed && before.Managed == file.Managed && bytes.Equal(before.Content, file.Content) {
+			continue
+		}
+
+		if g.config.IsUserScope() {
+			reason, err := owned.Protected(outputDir, file)
+			if err != nil {
+				return nil, changes, fmt.Errorf("failed to check %s: %w", file.Path, err)
+			}
+			if reason != "" {
+				changes.Skipped = append(changes.Skipped, file.Path)
+				continue
+			}
+		}
+
+		if _, err := output.Write(outputDir, file); err != nil {
+			return nil, changes, err
+		}
+		if existed {
+			changes.Updated = append(changes.Updated, file.Path)
+		} else {
+			changes.Added = append(changes.Added, file.Path)
+		}
+	}
+
+	if previous != nil {
+		for _, file := range previous.Files() {
+			if _, ok := plan.Get(file.Path); !ok {
+				changes.Removed = append(changes.Removed, file.Path)
+			}
+		}
+	}
+
+	stale, err := g.staleEntries(plan, owned)
+	if err != nil {
+		return nil, changes, err
+	}
+	if err := g.saveManifest(plan, owned, changes.Skipped, stale); err != nil {
+		return nil, changes, err
+	}
+	return plan, changes, nil
+}
+
+// Export plans the files and writes them to w in the given format (see
+// internal/export) instead of writing them to the output directory. It
+// returns the number of files in the archive, or changed by the patch.
+func (g *Generator) Export(format string, w io.Writer) (int, error) {
+	plan, err := g.Plan()
+	if err != nil {
+		return 0, err
+	}
+
+	switch format {
+	case export.FormatZip, export.FormatTarGz:
+		// Archived files are written on their own, without the files of
+		// the output directory to patch
+		m, err := manifest.New(plan, "")
+		if err != nil {
+			return 0, err
+		}
+		if format == export.FormatZip {
+			return plan.Len(), export.Zip(w, plan, m)
+		}
+		return plan.Len(), export.TarGz(w, plan, m)
+	case export.FormatPatch:
+		// The patch updates the output directory's manifest like a run
+		// would, keeping the files that are no longer generated listed
+		previous, err := manifest.Load(g.config.OutputDir)
+		if err != nil {
+			return 0, err
+		}
+		stale, err := g.staleEntries(plan, previous)
+		if err != nil {
+			return 0, err
+		}
+		next, err := manifest.New(plan, g.config.OutputDir)
+		if err != nil {
+			return 0, err
+		}
+		next.Keep(stale)
+		return export.Patch(w, plan, next, g.config.OutputDir)
+	}
+	return 0, fmt.Errorf("unknown format %q (available: %s)", format, strings.Join(export.Formats, ", "))
+}
+
+// DryRun plans the files for all selected providers and prints them as a
+// tree, with their sizes and source templates, without writing anything
+func (g *Generator) DryRun() error {
+	absOutputDir, err := filepath.Abs(g.config.OutputDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve output path: %w", err)
+	}
+
+	fmt.Printf("\nUsing %s templates\n", g.fsType)
+	fmt.Printf("Dry run: nothing will be written to %s\n\n", absOutputDir)
+
+	plan, err := g.Plan()
+	if err != nil {
+		return err
+	}
+
+	output.PrintTree(os.Stdout, plan, g.config.OutputDir)
+	fmt.Println()
+	g.printDuplication(plan)
+	printWarnings(plan)
+	fmt.Printf("%d files, %s\n", plan.Len(), output.FormatSize(plan.Size()))
+	return nil
+}
+
+// Plan runs every selected provider and returns the files they would write
+func (g *Generator) Plan() (*output.Plan, error) {
+	// Infer stack globs from the project layout if requested
+	if g.config.InferGlobs {
+		if err := g.inferGlobs(); err != nil {
+			return nil, err
+		}
+	}
+
+	plan := output.NewPlan()
 	for _, providerName := range g.config.Providers {
 		provider, ok := providers.Get(providerName)
 		if !ok {
@@ -53,13 +350,149 @@ func (g *Generator) Run() error {
 			continue
 		}
 
-		fmt.Printf("Generating for %s...\n", providerName)
-		if err := provider.Generate(g.config, g.fs, outputDir); err != nil {
-			return fmt.Errorf("failed to generate for %s: %w", providerName, err)
+		if err := provider.Generate(g.config, g.fs, plan); err != nil {
+			return nil, fmt.Errorf("failed to generate for %s: %w", providerName, err)
+		}
+	}
+
+	if collisions := plan.Collisions(); len(collisions) > 0 {
+		var lines []string
+		for _, collision := range collisions {
+			lines = append(lines, "  "+collision.Error())
+		}
+		return nil, fmt.Errorf("selected providers write conflicting files:\n%s\nDeselect one of the providers or generate them into separate output directories", strings.Join(lines, "\n"))
+	}
+
+	sources, err := g.readSources(plan)
+	if err != nil {
+		return nil, err
+	}
+
+	g.references = nil
+	if g.config.Dedupe {
+		g.references = dedupe.Apply(plan, sources)
+	}
+
+	g.annotate(plan, sources)
+	return plan, nil
+}
+
+// readSources reads the templates every planned file was built from
+func (g *Generator) readSources(plan *output.Plan) (map[string][]byte, error) {
+	sources := make(map[string][]byte)
+	for _, file := range plan.Files() {
+		for _, source := range file.Sources {
+			if _, ok := sources[source]; ok {
+				continue
+			}
+			data, err := g.fs.ReadFile(source)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", source, err)
+			}
+			sources[source] = data
+		}
+	}
+	return sources, nil
+}
+
+// annotate adds provenance headers to markdown files if requested, and
+// records which template each line of every planned file came from
+func (g *Generator) annotate(plan *output.Plan, sources map[string][]byte) {
+	plan.Update(func(f *output.File) {
+		if g.config.Provenance && strings.HasSuffix(f.Path, ".md") {
+			f.Content = provenance.Insert(f.Content, provenance.Header(*f, sources, version.Version))
+		}
+		f.SourceMap = provenance.Map(f.Content, f.Sources, sources)
+	})
+}
+
+// maxDuplicatesShown is how many groups of duplicated templates a run lists
+const maxDuplicatesShown = 10
+
+// printDuplication reports what dedupe changed and the templates that are
+// still copied into more than one file
+func (g *Generator) printDuplication(plan *output.Plan) {
+	if len(g.references) > 0 {
+		fmt.Println("Deduplicated:")
+		for _, reference := range g.references {
+			fmt.Printf("  %s\n", reference)
 		}
 		fmt.Println()
 	}
 
-	fmt.Println("Generation complete!")
+	duplicates := dedupe.Find(plan)
+	if len(duplicates) == 0 {
+		return
+	}
+	templates, copies := 0, 0
+	for _, duplicate := range duplicates {
+		templates += len(duplicate.Sources)
+		copies += len(duplicate.Sources) * len(duplicate.Paths)
+	}
+	fmt.Printf("Duplicated: %d templates are copied into more than one file, %d copies in all\n", templates, copies)
+	for i, duplicate := range duplicates {
+		if i == maxDuplicatesShown {
+			fmt.Printf("  ... and %d more\n", len(duplicates)-i)
+			break
+		}
+		fmt.Printf("  %s\n    in %s\n", strings.Join(duplicate.Sources, ", "), strings.Join(duplicate.Paths, ", "))
+	}
+	if !g.config.Dedupe && hasCanonical(plan) {
+		fmt.Println("Set dedupe: true in agentspack.yaml or pass --dedupe to refer to AGENTS.md instead where the tools allow it")
+	}
+	fmt.Println()
+}
+
+// printWarnings prints what providers couldn't generate as the templates intend
+func printWarnings(plan *output.Plan) {
+	warnings := plan.Warnings()
+	if len(warnings) == 0 {
+		return
+	}
+	for _, warning := range warnings {
+		fmt.Printf("Warning (%s): %s\n", warning.Provider, warning.Message)
+	}
+	fmt.Println()
+}
+
+// hasCanonical reports whether any planned file can be referred to with dedupe
+func hasCanonical(plan *output.Plan) bool {
+	for _, f := range plan.Files() {
+		if f.Canonical {
+			return true
+		}
+	}
+	return false
+}
+
+// inferGlobs scans the project directory and records where each selected
+// stack's files live, so providers can scope rules to the real layout
+func (g *Generator) inferGlobs() error {
+	projectDir := g.config.ProjectDir
+	if projectDir == "" {
+		projectDir = "."
+	}
+
+	registry, err := stacks.Load(g.fs)
+	if err != nil {
+		return err
+	}
+
+	inferred, err := detect.InferGlobs(projectDir, registry.Select(g.config.TechStacks))
+	if err != nil {
+		return fmt.Errorf("failed to infer globs from %s: %w", projectDir, err)
+	}
+	g.config.InferredGlobs = inferred
+
+	// Reported on stderr, so the output of commands like diff stays clean
+	fmt.Fprintf(os.Stderr, "Inferred globs from %s:\n", projectDir)
+	for _, stack := range g.config.TechStacks {
+		if globs, ok := inferred[stack]; ok {
+			fmt.Fprintf(os.Stderr, "  %s: %s\n", stack, strings.Join(globs, ", "))
+		} else {
+			fmt.Fprintf(os.Stderr, "  %s: no matching files, using defaults\n", stack)
+		}
+	}
+	fmt.Fprintln(os.Stderr)
 	return nil
 }
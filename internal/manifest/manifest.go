@@ -0,0 +1,278 @@
+package manifest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/agentspack/agentspack/internal/output"
+	"github.com/agentspack/agentspack/internal/version"
+)
+
+// FileName is where the manifest is stored, relative to the output directory
+var FileName = filepath.Join(".agentspack", "manifest.json")
+
+// Manifest lists the files agentspack generated into an output directory
+type Manifest struct {
+	Version string  `json:"version"` // agentspack version that wrote the manifest
+	Files   []Entry `json:"files"`   // Sorted by path
+}
+
+// Entry is a generated file
+type Entry struct {
+	Path     string   `json:"path"`   // Slash-separated, relative to the output directory
+	SHA256   string   `json:"sha256"` // Hash of the content as generated
+	Provider string   `json:"provider,omitempty"`
+	Sources  []string `json:"sources,omitempty"` // Template paths the content was built from
+	Managed  bool     `json:"managed,omitempty"` // Only the managed block of the file was generated
+
+	Settings []output.Setting `json:"settings,omitempty"` // Only these settings of a shared config file were generated
+
+	Transform string        `json:"transform,omitempty"`  // How the provider built the content
+	SourceMap []output.Span `json:"source_map,omitempty"` // Which template each line came from
+}
+
+// FileState is the state of a generated file on disk
+type FileState int
+
+const (
+	Unchanged FileState = iota // Content matches the hash it was generated with
+	Edited                     // Content was changed since it was generated
+	Missing                    // File no longer exists
+)
+
+// New creates the manifest for the files of a plan as they are written
+// into outputDir. Patched config files are hashed as they are once patched,
+// since the rest of the file isn't generated; pass an empty outputDir when
+// the files are written on their own, as in an archive.
+func New(plan *output.Plan, outputDir string) (*Manifest, error) {
+	m := &Manifest{Version: version.Version}
+	for _, file := range plan.Files() {
+		content := file.Content
+		switch {
+		case file.Managed:
+			content = output.BlockContent(content)
+		case file.Patch != nil && outputDir != "":
+			current, err := os.ReadFile(filepath.Join(outputDir, filepath.FromSlash(file.Path)))
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return nil, err
+			}
+			if content, err = output.Render(file, current); err != nil {
+				return nil, err
+			}
+		}
+		m.Files = append(m.Files, Entry{
+			Path:     file.Path,
+			SHA256:   Hash(content),
+			Provider: file.Provider,
+			Sources:  file.Sources,
+			Managed:  file.Managed,
+			Settings: file.Settings,
+
+			Transform: file.Transform,
+			SourceMap: file.SourceMap,
+		})
+	}
+	m.sort()
+	return m, nil
+}
+
+// Hash returns the hex-encoded SHA-256 of content
+func Hash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// Load reads the manifest of outputDir. It returns nil without an error
+// when the directory has no manifest.
+func Load(outputDir string) (*Manifest, error) {
+	path := filepath.Join(outputDir, FileName)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+	for _, entry := range m.Files {
+		if _, err := Resolve(outputDir, entry.Path); err != nil {
+			return nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+		}
+	}
+	return &m, nil
+}
+
+// Resolve returns where a manifest path is on disk. The manifest is
+// usually committed, so paths that are absolute or lead out of outputDir
+// are rejected rather than followed.
+func Resolve(outputDir, path string) (string, error) {
+	native := filepath.FromSlash(path)
+	if path == "" || filepath.IsAbs(native) || filepath.VolumeName(native) != "" {
+		return "", fmt.Errorf("path %q is not relative to the output directory", path)
+	}
+	root := filepath.Clean(outputDir)
+	target := filepath.Join(root, native)
+	rel, err := filepath.Rel(root, target)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path %q leads out of the output directory", path)
+	}
+	return target, nil
+}
+
+// Save writes the manifest into outputDir
+func (m *Manifest) Save(outputDir string) error {
+	data, err := m.Marshal()
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(outputDir, FileName)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create manifest directory: %w", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Marshal encodes the manifest as it is saved, sorted by path
+func (m *Manifest) Marshal() ([]byte, error) {
+	m.sort()
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// Get returns the entry for a path
+func (m *Manifest) Get(path string) (Entry, bool) {
+	if m == nil {
+		return Entry{}, false
+	}
+	for _, entry := range m.Files {
+		if entry.Path == path {
+			return entry, true
+		}
+	}
+	return Entry{}, false
+}
+
+// Stale returns the entries of files the plan no longer produces
+func (m *Manifest) Stale(plan *output.Plan) []Entry {
+	if m == nil {
+		return nil
+	}
+	var stale []Entry
+	for _, entry := range m.Files {
+		if _, ok := plan.Get(entry.Path); !ok {
+			stale = append(stale, entry)
+		}
+	}
+	return stale
+}
+
+// Keep adds entries of files that stay owned by agentspack although they
+// are no longer generated, so a later clean can still remove them
+func (m *Manifest) Keep(entries []Entry) {
+	for _, entry := range entries {
+		if _, ok := m.Get(entry.Path); !ok {
+			m.Files = append(m.Files, entry)
+		}
+	}
+	m.sort()
+}
+
+// State checks a generated file on disk against the hash it was generated
+// with. For managed files only the managed block is checked; a file whose
+// block is gone counts as missing.
+func (e Entry) State(outputDir string) (FileState, error) {
+	target, err := Resolve(outputDir, e.Path)
+	if err != nil {
+		return Missing, err
+	}
+	data, err := os.ReadFile(target)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return Missing, nil
+		}
+		return 0, err
+	}
+	if e.Managed {
+		block, ok, err := output.ExtractBlock(data)
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			return Missing, nil
+		}
+		data = block
+	}
+	if Hash(data) != e.SHA256 {
+		return Edited, nil
+	}
+	return Unchanged, nil
+}
+
+// Protected explains why a planned file must not replace the file on disk:
+// it exists without agentspack having generated it, or it was edited since.
+// It returns an empty string when the file can be written.
+func (m *Manifest) Protected(outputDir string, file output.File) (string, error) {
+	if file.Patch != nil {
+		// Only the settings are written, keeping the rest
+		return "", nil
+	}
+	if entry, ok := m.Get(file.Path); ok {
+		state, err := entry.State(outputDir)
+		if err != nil {
+			return "", err
+		}
+		if state == Edited {
+			return "edited since it was generated", nil
+		}
+		return "", nil
+	}
+	if file.Managed {
+		// Only the block is written, keeping the rest
+		return "", nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(outputDir, filepath.FromSlash(file.Path)))
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return "", nil
+	case err != nil:
+		return "", err
+	case bytes.Equal(data, file.Content):
+		return "", nil
+	}
+	return "not generated by agentspack", nil
+}
+
+// Forget removes the entry for a path
+func (m *Manifest) Forget(path string) {
+	for i, entry := range m.Files {
+		if entry.Path == path {
+			m.Files = append(m.Files[:i], m.Files[i+1:]...)
+			return
+		}
+	}
+}
+
+// sort orders the entries by path
+func (m *Manifest) sort() {
+	sort.Slice(m.Files, func(i, j int) bool {
+		return m.Files[i].Path < m.Files[j].Path
+	})
+}
@@ -0,0 +1,205 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/agentspack/agentspack/internal/output"
+)
+
+func TestSaveAndLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	plan := output.NewPlan()
+	plan.Add(output.File{Path: "AGENTS.md", Content: []byte("agents\n"), Provider: "codex", Sources: []string{"system/base/base.md"}})
+	plan.Add(output.File{Path: ".codex/skills/react-guidelines/SKILL.md", Content: []byte("react\n"), Provider: "codex"})
+
+	m, err := New(plan, dir)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if err := m.Save(dir); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	loaded, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if len(loaded.Files) != 2 || loaded.Files[0].Path != ".codex/skills/react-guidelines/SKILL.md" {
+		t.Fatalf("expected entries sorted by path, got %+v", loaded.Files)
+	}
+	entry, ok := loaded.Get("AGENTS.md")
+	if !ok || entry.SHA256 != Hash([]byte("agents\n")) || entry.Provider != "codex" || len(entry.Sources) != 1 {
+		t.Fatalf("unexpected entry %+v", entry)
+	}
+
+	missing, err := Load(t.TempDir())
+	if err != nil || missing != nil {
+		t.Fatalf("expected no manifest, got %+v (%v)", missing, err)
+	}
+}
+
+func TestPruneKeepsEditedFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, ".cursor/rules/agent-old/RULE.md", "old agent\n")
+	writeFile(t, dir, ".cursor/rules/agent-edited/RULE.md", "edited by hand\n")
+
+	previous := &Manifest{Files: []Entry{
+		{Path: ".cursor/rules/agent-old/RULE.md", SHA256: Hash([]byte("old agent\n"))},
+		{Path: ".cursor/rules/agent-edited/RULE.md", SHA256: Hash([]byte("as generated\n"))},
+		{Path: ".cursor/rules/agent-gone/RULE.md", SHA256: Hash([]byte("gone\n"))},
+		{Path: "AGENTS.md", SHA256: Hash([]byte("agents\n"))},
+	}}
+	plan := output.NewPlan()
+	plan.Add(output.File{Path: "AGENTS.md", Content: []byte("agents\n")})
+
+	stale := previous.Stale(plan)
+	if len(stale) != 3 {
+		t.Fatalf("expected 3 stale entries, got %+v", stale)
+	}
+
+	kept, err := Prune(dir, stale, nil, false)
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if len(kept) != 1 || kept[0].Path != ".cursor/rules/agent-edited/RULE.md" {
+		t.Fatalf("expected the edited file to be kept, got %+v", kept)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".cursor", "rules", "agent-old")); !os.IsNotExist(err) {
+		t.Fatalf("expected the emptied directory to be removed, got %v", err)
+	}
+
+	kept, err = Prune(dir, kept, func(string) bool { return true }, false)
+	if err != nil || len(kept) != 0 {
+		t.Fatalf("expected the confirmed file to be removed, got %+v (%v)", kept, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".cursor")); !os.IsNotExist(err) {
+		t.Fatalf("expected .cursor to be removed once empty, got %v", err)
+	}
+}
+
+func TestProtectedKeepsUnownedAndEditedFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, ".claude/agents/mine.md", "written by the user\n")
+	writeFile(t, dir, ".claude/agents/same.md", "same\n")
+	writeFile(t, dir, ".claude/agents/edited.md", "edited\n")
+	writeFile(t, dir, ".claude/agents/owned.md", "owned\n")
+
+	previous := &Manifest{Files: []Entry{
+		{Path: ".claude/agents/edited.md", SHA256: Hash([]byte("as generated\n"))},
+		{Path: ".claude/agents/owned.md", SHA256: Hash([]byte("owned\n"))},
+	}}
+	for path, protected := range map[string]bool{
+		".claude/agents/mine.md":   true,
+		".claude/agents/same.md":   false,
+		".claude/agents/edited.md": true,
+		".claude/agents/owned.md":  false,
+		".claude/agents/new.md":    false,
+	} {
+		reason, err := previous.Protected(dir, output.File{Path: path, Content: []byte("same\n")})
+		if err != nil {
+			t.Fatalf("Protected failed: %v", err)
+		}
+		if (reason != "") != protected {
+			t.Errorf("%s: expected protected=%v, got reason %q", path, protected, reason)
+		}
+	}
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPathsOutsideOutputDirAreRejected(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "out")
+	outside := filepath.Join(root, "x")
+	if err := os.WriteFile(outside, []byte("keep\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{"../x", "a/../../x", outside, "."} {
+		entry := Entry{Path: filepath.ToSlash(path), SHA256: Hash([]byte("keep\n"))}
+		if _, err := Prune(dir, []Entry{entry}, nil, false); err == nil {
+			t.Errorf("expected %q to be rejected", path)
+		}
+
+		m := &Manifest{Files: []Entry{entry}}
+		if err := m.Save(dir); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		if _, err := Load(dir); err == nil {
+			t.Errorf("expected a manifest listing %q to be rejected", path)
+		}
+	}
+
+	if _, err := os.Stat(outside); err != nil {
+		t.Errorf("expected the file outside the output directory to be kept: %v", err)
+	}
+}
+
+func TestPruneKeepsSharedConfigFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, ".aider.conf.yml", "model: gpt-4\nread: NOTES.md\n")
+
+	// The config file existed before agentspack added CONVENTIONS.md to it
+	readConventions := func(current []byte) ([]byte, error) {
+		return []byte(strings.Replace(string(current), "read: NOTES.md", "read:\n  - NOTES.md\n  - CONVENTIONS.md", 1)), nil
+	}
+	plan := output.NewPlan()
+	plan.Add(output.File{
+		Path:     ".aider.conf.yml",
+		Content:  []byte("read:\n  - CONVENTIONS.md\n"),
+		Patch:    readConventions,
+		Settings: []output.Setting{{Key: "read", Item: "CONVENTIONS.md"}},
+	})
+	if _, err := output.Write(dir, plan.Files()[0]); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	m, err := New(plan, dir)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	entry, _ := m.Get(".aider.conf.yml")
+	if state, err := entry.State(dir); err != nil || state != Unchanged {
+		t.Fatalf("expected the patched file to be unchanged, got %v (%v)", state, err)
+	}
+	if reason, err := m.Protected(dir, plan.Files()[0]); err != nil || reason != "" {
+		t.Fatalf("expected the patched file to stay writable, got %q (%v)", reason, err)
+	}
+
+	// Once the file is no longer generated only CONVENTIONS.md is removed,
+	// even after the other settings were edited and the removal confirmed
+	writeFile(t, dir, ".aider.conf.yml", "model: gpt-4o\nread:\n  - NOTES.md\n  - CONVENTIONS.md\n")
+	kept, err := Prune(dir, m.Stale(output.NewPlan()), func(string) bool { return true }, false)
+	if err != nil || len(kept) != 0 {
+		t.Fatalf("expected the settings to be removed, got %+v (%v)", kept, err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, ".aider.conf.yml"))
+	if err != nil {
+		t.Fatalf("expected the config file to be kept: %v", err)
+	}
+	if want := "model: gpt-4o\nread:\n  - NOTES.md\n"; string(data) != want {
+		t.Errorf("expected %q, got %q", want, data)
+	}
+
+	// A config file holding nothing but the settings goes
+	writeFile(t, dir, ".aider.conf.yml", "read:\n  - CONVENTIONS.md\n")
+	if _, err := Prune(dir, []Entry{entry}, func(string) bool { return true }, false); err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".aider.conf.yml")); !os.IsNotExist(err) {
+		t.Errorf("expected the emptied config file to be removed, got %v", err)
+	}
+}
@@ -0,0 +1,136 @@
+package manifest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/agentspack/agentspack/internal/output"
+)
+
+// Prune removes stale generated files from outputDir and returns the entries
+// of the files it kept. Files edited since they were generated are only
+// removed when confirm returns true, and kept when confirm is nil. With
+// dryRun nothing is removed and every existing file is reported as kept.
+func Prune(outputDir string, stale []Entry, confirm func(path string) bool, dryRun bool) ([]Entry, error) {
+	var kept []Entry
+	for _, entry := range stale {
+		state, err := entry.State(outputDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check %s: %w", entry.Path, err)
+		}
+
+		name := entry.Path
+		switch {
+		case entry.Managed:
+			name = "managed block in " + entry.Path
+		case len(entry.Settings) > 0:
+			name = "agentspack settings in " + entry.Path
+		}
+
+		switch {
+		case state == Missing:
+			continue
+		case dryRun && state == Edited:
+			fmt.Printf("  Would ask before removing: %s (edited by hand)\n", name)
+			kept = append(kept, entry)
+			continue
+		case dryRun:
+			fmt.Printf("  Would remove: %s\n", name)
+			kept = append(kept, entry)
+			continue
+		case state == Edited && (confirm == nil || !confirm(entry.Path)):
+			fmt.Printf("  Kept: %s (edited by hand)\n", name)
+			kept = append(kept, entry)
+			continue
+		}
+
+		switch {
+		case entry.Managed:
+			err = RemoveBlock(outputDir, entry.Path)
+		case len(entry.Settings) > 0:
+			err = RemoveSettings(outputDir, entry.Path, entry.Settings)
+		default:
+			err = Remove(outputDir, entry.Path)
+		}
+		if err != nil {
+			return nil, err
+		}
+		fmt.Printf("  Removed: %s\n", name)
+	}
+	return kept, nil
+}
+
+// RemoveBlock removes the managed block from a file, keeping the
+// hand-written content around it. The file is deleted when nothing else is left.
+func RemoveBlock(outputDir, path string) error {
+	target, err := Resolve(outputDir, path)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(target)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	rest, err := output.RemoveBlock(data)
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", path, err)
+	}
+	if len(rest) == 0 {
+		return Remove(outputDir, path)
+	}
+	if err := os.WriteFile(target, rest, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// RemoveSettings removes the settings agentspack set from a shared config
+// file, keeping the others. The file is deleted when no other settings are left.
+func RemoveSettings(outputDir, path string, settings []output.Setting) error {
+	target, err := Resolve(outputDir, path)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(target)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	rest, err := output.RemoveSettings(path, data, settings)
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", path, err)
+	}
+	if len(rest) == 0 {
+		return Remove(outputDir, path)
+	}
+	if err := os.WriteFile(target, rest, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// Remove deletes a generated file and any directories it leaves empty,
+// up to outputDir
+func Remove(outputDir, path string) error {
+	target, err := Resolve(outputDir, path)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove %s: %w", path, err)
+	}
+
+	root := filepath.Clean(outputDir)
+	for dir := filepath.Dir(target); dir != root && len(dir) > len(root); dir = filepath.Dir(dir) {
+		entries, err := os.ReadDir(dir)
+		if err != nil || len(entries) > 0 {
+			break
+		}
+		if err := os.Remove(dir); err != nil {
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Markers delimiting the managed block of a file that also holds hand-written content
+const (
+	BlockBegin = "<!-- agentspack:begin -->"
+	BlockEnd   = "<!-- agentspack:end -->"
+)
+
+// Render returns the content a planned file should have on disk, given the
+// current content of the file (nil when it doesn't exist). Managed files
+// only replace their block, keeping the content around it, and patched
+// files only change their settings.
+func Render(f File, current []byte) ([]byte, error) {
+	switch {
+	case f.Managed:
+		merged, err := MergeBlock(current, f.Content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update %s: %w", f.Path, err)
+		}
+		return merged, nil
+	case f.Patch != nil && current != nil:
+		patched, err := f.Patch(current)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update %s: %w", f.Path, err)
+		}
+		return patched, nil
+	}
+	return f.Content, nil
+}
+
+// BlockContent returns content as it appears inside a managed block,
+// which always ends with a newline
+func BlockContent(content []byte) []byte {
+	if len(content) > 0 && !bytes.HasSuffix(content, []byte("\n")) {
+		return append(append([]byte{}, content...), '\n')
+	}
+	return content
+}
+
+// MergeBlock replaces the managed block in existing with content. When
+// existing has no block, the block is appended after its content.
+func MergeBlock(existing, content []byte) ([]byte, error) {
+	var block bytes.Buffer
+	block.WriteString(BlockBegin + "\n")
+	block.Write(BlockContent(content))
+	block.WriteString(BlockEnd + "\n")
+
+	start, end, ok, err := findBlock(existing)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		trimmed := bytes.TrimRight(existing, " \t\r\n")
+		if len(trimmed) == 0 {
+			return block.Bytes(), nil
+		}
+		return append(append(append([]byte{}, trimmed...), "\n\n"...), block.Bytes()...), nil
+	}
+
+	merged := append([]byte{}, existing[:start]...)
+	merged = append(merged, block.Bytes()...)
+	return append(merged, existing[end:]...), nil
+}
+
+// ExtractBlock returns the content of the managed block in data
+func ExtractBlock(data []byte) ([]byte, bool, error) {
+	start, end, ok, err := findBlock(data)
+	if !ok {
+		return nil, false, err
+	}
+	block := data[start:end]
+	inner := block[bytes.IndexByte(block, '\n')+1 : bytes.LastIndex(block, []byte(BlockEnd))]
+	return inner, true, nil
+}
+
+// RemoveBlock returns data without its managed block. The result is empty
+// when nothing but whitespace remains.
+func RemoveBlock(data []byte) ([]byte, error) {
+	start, end, ok, err := findBlock(data)
+	if !ok {
+		return data, err
+	}
+
+	before := bytes.TrimRight(data[:start], " \t\r\n")
+	after := bytes.TrimLeft(data[end:], " \t\r\n")
+	switch {
+	case len(before) == 0 && len(after) == 0:
+		return nil, nil
+	case len(before) == 0:
+		return after, nil
+	case len(after) == 0:
+		return append(append([]byte{}, before...), '\n'), nil
+	}
+	rest := append(append([]byte{}, before...), "\n\n"...)
+	return append(rest, after...), nil
+}
+
+// findBlock locates the managed block, from the start of its begin marker
+// line to the end of its end marker line. A marker without its pair is an
+// error rather than no block: a block appended after it would be paired
+// with the stray marker on the next run, replacing the content between them.
+func findBlock(data []byte) (start, end int, ok bool, err error) {
+	start = bytes.Index(data, []byte(BlockBegin))
+	endMarker := bytes.Index(data, []byte(BlockEnd))
+	switch {
+	case start == -1 && endMarker == -1:
+		return 0, 0, false, nil
+	case endMarker == -1:
+		return 0, 0, false, fmt.Errorf("%s has no %s after it", BlockBegin, BlockEnd)
+	case start == -1 || endMarker < start:
+		return 0, 0, false, fmt.Errorf("%s has no %s before it", BlockEnd, BlockBegin)
+	}
+
+	end = endMarker + len(BlockEnd)
+	if end < len(data) && data[end] == '\r' {
+		end++
+	}
+	if end < len(data) && data[end] == '\n' {
+		end++
+	}
+	return start, end, true, nil
+}
@@ -0,0 +1,113 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Merge is how a file is combined with files other providers plan at the same path
+type Merge int
+
+const (
+	// MergeNone makes a second provider planning the path a collision
+	MergeNone Merge = iota
+	// MergeSections keeps the content the providers share once, followed by
+	// one section per provider in the order they were planned
+	MergeSections
+)
+
+// Collision is a path planned by two providers whose files can't be merged
+type Collision struct {
+	Path     string
+	Planned  string // Provider that planned the path first
+	Provider string // Provider whose file collided with it
+	Reason   string
+}
+
+func (c Collision) Error() string {
+	return fmt.Sprintf("%s is generated by both %s and %s: %s", c.Path, c.Planned, c.Provider, c.Reason)
+}
+
+// section is one provider's part of a merged file
+type section struct {
+	provider string
+	content  []byte
+}
+
+// Providers returns the providers whose content the file holds, the
+// planning provider first
+func (f File) Providers() []string {
+	if len(f.sections) == 0 {
+		return []string{f.Provider}
+	}
+	providers := make([]string, len(f.sections))
+	for i, s := range f.sections {
+		providers[i] = s.provider
+	}
+	return providers
+}
+
+// mergeFiles combines a planned file with one another provider planned at
+// the same path, or explains why they can't be combined
+func mergeFiles(planned, f File) (File, string) {
+	switch {
+	case planned.Merge != MergeSections:
+		return File{}, fmt.Sprintf("%s doesn't declare a merge strategy for it", planned.Provider)
+	case f.Merge != MergeSections:
+		return File{}, fmt.Sprintf("%s doesn't declare a merge strategy for it", f.Provider)
+	case planned.Managed != f.Managed:
+		return File{}, "only one of them is a managed block"
+	case !bytes.Equal(planned.Shared, f.Shared):
+		return File{}, "their shared content differs"
+	}
+
+	sections := planned.sections
+	if len(sections) == 0 {
+		sections = []section{providerSection(planned)}
+	}
+	merged := planned
+	merged.sections = append(append([]section{}, sections...), providerSection(f))
+	merged.Sources = appendMissing(planned.Sources, f.Sources)
+	if f.Transform != planned.Transform {
+		merged.Transform = planned.Transform + "; " + f.Transform
+	}
+
+	var buf bytes.Buffer
+	if shared := bytes.TrimSpace(planned.Shared); len(shared) > 0 {
+		buf.Write(shared)
+		buf.WriteString("\n\n---\n\n")
+	}
+	for i, s := range merged.sections {
+		if i > 0 {
+			buf.WriteString("\n\n---\n\n")
+		}
+		buf.Write(s.content)
+	}
+	buf.WriteString("\n")
+	merged.Content = buf.Bytes()
+	return merged, ""
+}
+
+// providerSection returns the part of a file's content that follows its shared content
+func providerSection(f File) section {
+	return section{
+		provider: f.Provider,
+		content:  bytes.TrimSpace(bytes.TrimPrefix(f.Content, f.Shared)),
+	}
+}
+
+// appendMissing appends the values of extra not already in values
+func appendMissing(values, extra []string) []string {
+	result := append([]string{}, values...)
+	seen := make(map[string]bool, len(values))
+	for _, v := range values {
+		seen[v] = true
+	}
+	for _, v := range extra {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
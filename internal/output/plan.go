@@ -0,0 +1,173 @@
+package output
+
+import (
+	"os"
+	"path"
+)
+
+// DefaultMode is the permission mode of planned files that don't set one
+const DefaultMode os.FileMode = 0644
+
+// File is a file a provider plans to write
+type File struct {
+	Path      string      // Slash-separated, relative to the output directory (e.g. ".cursor/rules/global/RULE.md")
+	Content   []byte      // File content, or the content of the managed block when Managed
+	Mode      os.FileMode // Permission mode, DefaultMode when zero
+	Sources   []string    // Template paths the content was built from (e.g. "system/base/base.md")
+	Transform string      // How the provider built the content from its sources (e.g. "agent converted to a sub-agent with frontmatter")
+	SourceMap []Span      // Which source each line of the content came from, filled in by the generator
+	Provider  string      // Name of the provider that planned the file
+	Managed   bool        // Content is a managed block merged into the existing file (see MergeBlock)
+	Merge     Merge       // How the file combines with files other providers plan at the same path
+	Shared    []byte      // Leading part of Content other providers share when merging sections
+	Canonical bool        // Other tools read the file too, so with dedupe it is the one copy of the templates it holds
+	Import    string      // With dedupe, the line that imports a canonical file instead of repeating its templates, %s being its relative path (e.g. "@%s")
+	Covered   bool        // With dedupe, the file is left out when a canonical file its tool reads holds all its templates
+	Patch     Patch       // Updates a config file the user shares instead of replacing it; Content is the result for a new file
+	Settings  []Setting   // The settings Patch sets, removed instead of the file once it is no longer generated
+	PartOf    string      // Path of the first part, for a later part of a file split to fit its tool's size limit
+
+	sections []section // Each provider's part of a file merged from several providers
+}
+
+// Patch updates the current content of a config file (e.g. a JSON or YAML
+// settings file) with only the settings agentspack manages, keeping the
+// rest. It isn't called when the file doesn't exist yet; Content is
+// written then.
+type Patch func(current []byte) ([]byte, error)
+
+// Span attributes consecutive lines of a planned file's content to a source template
+type Span struct {
+	Start      int    `json:"start"`                 // First line of the span, 1-based
+	End        int    `json:"end"`                   // Last line of the span, inclusive
+	Source     string `json:"source,omitempty"`      // Template the lines were copied from, empty for lines the provider added
+	SourceLine int    `json:"source_line,omitempty"` // Line of Start in Source
+}
+
+// Warning is something a provider couldn't generate the way the templates
+// intend (e.g. content over a tool's size limit), reported with the run
+type Warning struct {
+	Provider string
+	Message  string
+}
+
+// Plan collects the files a generation run will write, in the order they were planned
+type Plan struct {
+	files      []File
+	index      map[string]int
+	collisions []Collision
+	warnings   []Warning
+}
+
+// NewPlan creates an empty plan
+func NewPlan() *Plan {
+	return &Plan{index: make(map[string]int)}
+}
+
+// Add plans a file. A file the same provider planned earlier at the same
+// path is replaced; one planned by another provider is merged with it
+// according to their Merge strategy, or recorded as a collision.
+func (p *Plan) Add(f File) {
+	f.Path = path.Clean(f.Path)
+	if f.Mode == 0 {
+		f.Mode = DefaultMode
+	}
+
+	if i, ok := p.index[f.Path]; ok {
+		planned := p.files[i]
+		if planned.Provider == f.Provider && len(planned.sections) == 0 {
+			p.files[i] = f
+			return
+		}
+
+		merged, reason := mergeFiles(planned, f)
+		if reason != "" {
+			p.collisions = append(p.collisions, Collision{
+				Path:     f.Path,
+				Planned:  planned.Provider,
+				Provider: f.Provider,
+				Reason:   reason,
+			})
+			return
+		}
+		p.files[i] = merged
+		return
+	}
+	p.index[f.Path] = len(p.files)
+	p.files = append(p.files, f)
+}
+
+// Get returns the file planned at the given path
+func (p *Plan) Get(filePath string) (File, bool) {
+	i, ok := p.index[path.Clean(filePath)]
+	if !ok {
+		return File{}, false
+	}
+	return p.files[i], true
+}
+
+// Remove drops the file planned at the given path, if any
+func (p *Plan) Remove(filePath string) {
+	i, ok := p.index[path.Clean(filePath)]
+	if !ok {
+		return
+	}
+	p.files = append(p.files[:i], p.files[i+1:]...)
+	p.index = make(map[string]int, len(p.files))
+	for j, f := range p.files {
+		p.index[f.Path] = j
+	}
+}
+
+// Update calls fn with each planned file so it can be amended in place.
+// fn must not change the file's path.
+func (p *Plan) Update(fn func(f *File)) {
+	for i := range p.files {
+		fn(&p.files[i])
+	}
+}
+
+// Warn records a warning for the run summary
+func (p *Plan) Warn(provider, message string) {
+	p.warnings = append(p.warnings, Warning{Provider: provider, Message: message})
+}
+
+// Warnings returns the warnings providers recorded, in the order they were recorded
+func (p *Plan) Warnings() []Warning {
+	return p.warnings
+}
+
+// Collisions returns the paths providers planned that couldn't be merged
+func (p *Plan) Collisions() []Collision {
+	return p.collisions
+}
+
+// Files returns every planned file, in the order they were planned
+func (p *Plan) Files() []File {
+	return p.files
+}
+
+// ByProvider returns the files planned by the given provider
+func (p *Plan) ByProvider(provider string) []File {
+	var files []File
+	for _, f := range p.files {
+		if f.Provider == provider {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+// Len returns the number of planned files
+func (p *Plan) Len() int {
+	return len(p.files)
+}
+
+// Size returns the total size of the planned files in bytes
+func (p *Plan) Size() int {
+	total := 0
+	for _, f := range p.files {
+		total += len(f.Content)
+	}
+	return total
+}
@@ -0,0 +1,149 @@
+package output
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPlanAddReplacesSamePath(t *testing.T) {
+	plan := NewPlan()
+	plan.Add(File{Path: "AGENTS.md", Content: []byte("codex"), Provider: "codex"})
+	plan.Add(File{Path: ".cursor/rules/global/RULE.md", Content: []byte("rule"), Provider: "cursor"})
+	plan.Add(File{Path: "./AGENTS.md", Content: []byte("replaced"), Provider: "codex"})
+
+	if plan.Len() != 2 {
+		t.Fatalf("expected 2 planned files, got %d", plan.Len())
+	}
+	file, ok := plan.Get("AGENTS.md")
+	if !ok || string(file.Content) != "replaced" {
+		t.Fatalf("expected AGENTS.md to be replaced, got %+v", file)
+	}
+	if file.Mode != DefaultMode {
+		t.Fatalf("expected default mode, got %v", file.Mode)
+	}
+	if files := plan.ByProvider("cursor"); len(files) != 1 || files[0].Path != ".cursor/rules/global/RULE.md" {
+		t.Fatalf("unexpected cursor files: %+v", files)
+	}
+}
+
+func TestPlanMergesSectionsAcrossProviders(t *testing.T) {
+	plan := NewPlan()
+	plan.Add(File{Path: "AGENTS.md", Content: []byte("base\n\ncursor\n"), Provider: "cursor", Sources: []string{"base.md", "Cursor.md"}, Merge: MergeSections, Shared: []byte("base\n")})
+	plan.Add(File{Path: "AGENTS.md", Content: []byte("base\n\ncodex\n"), Provider: "codex", Sources: []string{"base.md", "Codex.md"}, Merge: MergeSections, Shared: []byte("base\n")})
+
+	file, _ := plan.Get("AGENTS.md")
+	if want := "base\n\n---\n\ncursor\n\n---\n\ncodex\n"; string(file.Content) != want {
+		t.Fatalf("expected shared content once and a section per provider, got:\n%s", file.Content)
+	}
+	if providers := file.Providers(); len(providers) != 2 || providers[1] != "codex" {
+		t.Fatalf("unexpected providers %v", providers)
+	}
+	if len(file.Sources) != 3 || len(plan.ByProvider("cursor")) != 1 || len(plan.Collisions()) != 0 {
+		t.Fatalf("unexpected merged file %+v", file)
+	}
+
+	plan.Add(File{Path: "CLAUDE.md", Content: []byte("claude"), Provider: "claude-code"})
+	plan.Add(File{Path: "CLAUDE.md", Content: []byte("other"), Provider: "other"})
+	collisions := plan.Collisions()
+	if len(collisions) != 1 || collisions[0].Planned != "claude-code" || collisions[0].Provider != "other" {
+		t.Fatalf("expected a collision on CLAUDE.md, got %+v", collisions)
+	}
+	if file, _ := plan.Get("CLAUDE.md"); string(file.Content) != "claude" {
+		t.Fatalf("expected the colliding file not to overwrite CLAUDE.md, got %q", file.Content)
+	}
+}
+
+func TestWriteCreatesParentDirectories(t *testing.T) {
+	dir := t.TempDir()
+	outputPath, err := Write(dir, File{Path: ".claude/rules/global.md", Content: []byte("# Global\n")})
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if outputPath != filepath.Join(dir, ".claude", "rules", "global.md") {
+		t.Fatalf("unexpected output path %s", outputPath)
+	}
+	data, err := os.ReadFile(outputPath)
+	if err != nil || string(data) != "# Global\n" {
+		t.Fatalf("unexpected content %q (%v)", data, err)
+	}
+}
+
+func TestPrintTree(t *testing.T) {
+	plan := NewPlan()
+	plan.Add(File{Path: "AGENTS.md", Content: make([]byte, 2048), Sources: []string{"system/base/base.md"}, Provider: "codex"})
+	plan.Add(File{Path: ".codex/skills/backend-guidelines/SKILL.md", Content: []byte("skill"), Provider: "codex"})
+
+	var buf bytes.Buffer
+	PrintTree(&buf, plan, "out")
+
+	expected := strings.Join([]string{
+		"out/",
+		"├── .codex/",
+		"│   └── skills/",
+		"│       └── backend-guidelines/",
+		"│           └── SKILL.md (5 B, codex)",
+		"└── AGENTS.md (2.0 KB, codex)",
+		"      ← system/base/base.md",
+		"",
+	}, "\n")
+	if buf.String() != expected {
+		t.Fatalf("unexpected tree:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
+
+func TestMergeBlock(t *testing.T) {
+	hand := "# My project\n\nRun make test before pushing.\n"
+
+	merged, err := MergeBlock([]byte(hand), []byte("generated"))
+	want := hand + "\n" + BlockBegin + "\ngenerated\n" + BlockEnd + "\n"
+	if err != nil || string(merged) != want {
+		t.Fatalf("expected block appended after the hand-written content, got (%v):\n%s", err, merged)
+	}
+
+	edited := append(merged, "\nMore notes\n"...)
+	replaced, err := MergeBlock(edited, []byte("regenerated\n"))
+	want = hand + "\n" + BlockBegin + "\nregenerated\n" + BlockEnd + "\n\nMore notes\n"
+	if err != nil || string(replaced) != want {
+		t.Fatalf("expected only the block to be replaced, got (%v):\n%s", err, replaced)
+	}
+
+	inner, ok, err := ExtractBlock(replaced)
+	if err != nil || !ok || string(inner) != "regenerated\n" {
+		t.Fatalf("unexpected block content %q (%v)", inner, err)
+	}
+	if got, err := RemoveBlock(replaced); err != nil || string(got) != hand+"\nMore notes\n" {
+		t.Fatalf("expected the hand-written content to remain, got (%v):\n%s", err, got)
+	}
+	generated, _ := MergeBlock(nil, []byte("generated"))
+	if got, err := RemoveBlock(generated); err != nil || got != nil {
+		t.Fatalf("expected nothing to remain, got %q (%v)", got, err)
+	}
+}
+
+func TestUnmatchedBlockMarkers(t *testing.T) {
+	for name, data := range map[string]string{
+		"begin without end": "# Notes\n" + BlockBegin + "\nold\n\nMy own notes\n",
+		"end without begin": "# Notes\n" + BlockEnd + "\nMy own notes\n",
+		"end before begin":  BlockEnd + "\nMy own notes\n" + BlockBegin + "\nold\n",
+	} {
+		if _, err := MergeBlock([]byte(data), []byte("generated")); err == nil {
+			t.Errorf("%s: expected MergeBlock to fail rather than append a second block", name)
+		}
+		if _, _, err := ExtractBlock([]byte(data)); err == nil {
+			t.Errorf("%s: expected ExtractBlock to fail", name)
+		}
+		if _, err := RemoveBlock([]byte(data)); err == nil {
+			t.Errorf("%s: expected RemoveBlock to fail", name)
+		}
+	}
+
+	// The error names the file
+	f := File{Path: "CLAUDE.md", Content: []byte("generated"), Managed: true}
+	_, err := Render(f, []byte(BlockBegin+"\nMy own notes\n"))
+	if err == nil || !strings.Contains(err.Error(), "CLAUDE.md") {
+		t.Errorf("expected an error naming CLAUDE.md, got %v", err)
+	}
+}
@@ -0,0 +1,301 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Setting is a setting a Patch manages in a config file: a top-level key,
+// or an item of the list the key holds. It is recorded in the manifest so
+// the setting can be removed once the file is no longer generated.
+type Setting struct {
+	Key   string `json:"key"`             // Top-level key (e.g. "read")
+	Item  string `json:"item,omitempty"`  // Value of the key, or of the list item, that was set (e.g. "CONVENTIONS.md"); empty for the key whatever its value
+	Field string `json:"field,omitempty"` // For a list of mappings, the field of the item that holds Item (e.g. "slug")
+}
+
+// RemoveSettings returns a config file without the settings a Patch set,
+// keeping the rest. Files ending in .json are read as JSON, others as
+// YAML. The result is empty when no other settings remain.
+func RemoveSettings(path string, data []byte, settings []Setting) ([]byte, error) {
+	if strings.HasSuffix(path, ".json") {
+		return removeJSONSettings(data, settings)
+	}
+	return removeYAMLSettings(data, settings)
+}
+
+// jsonMember is a key of a JSON object with its value as written
+type jsonMember struct {
+	key   string
+	value json.RawMessage
+}
+
+// SetJSONKey sets key to value at the top level of the JSON object in data,
+// keeping the other keys, their order and their values. Empty data is an
+// empty object.
+func SetJSONKey(data []byte, key string, value any) ([]byte, error) {
+	members, err := jsonMembers(data)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	replaced := false
+	for i := range members {
+		if members[i].key == key {
+			members[i].value = encoded
+			replaced = true
+		}
+	}
+	if !replaced {
+		members = append(members, jsonMember{key: key, value: encoded})
+	}
+	return encodeJSON(members)
+}
+
+// removeJSONSettings removes settings from the JSON object in data
+func removeJSONSettings(data []byte, settings []Setting) ([]byte, error) {
+	members, err := jsonMembers(data)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, setting := range settings {
+		var kept []jsonMember
+		for _, member := range members {
+			if member.key != setting.Key {
+				kept = append(kept, member)
+				continue
+			}
+			value, err := removeJSONItem(member.value, setting)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for %q: %w", member.key, err)
+			}
+			if value != nil {
+				kept = append(kept, jsonMember{key: member.key, value: value})
+			}
+		}
+		members = kept
+	}
+
+	if len(members) == 0 {
+		return nil, nil
+	}
+	return encodeJSON(members)
+}
+
+// removeJSONItem returns the value of a setting's key without the setting,
+// or nil when the whole key goes
+func removeJSONItem(value json.RawMessage, setting Setting) (json.RawMessage, error) {
+	if setting.Item == "" {
+		return nil, nil
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(value, &items); err != nil {
+		// Not a list: the key goes when it holds the item
+		if jsonItemMatches(value, setting) {
+			return nil, nil
+		}
+		return value, nil
+	}
+
+	var kept []json.RawMessage
+	for _, item := range items {
+		if !jsonItemMatches(item, setting) {
+			kept = append(kept, item)
+		}
+	}
+	switch {
+	case len(kept) == 0:
+		return nil, nil
+	case len(kept) == len(items):
+		return value, nil
+	}
+	return json.Marshal(kept)
+}
+
+// jsonItemMatches reports whether a JSON value is the item of a setting
+func jsonItemMatches(value json.RawMessage, setting Setting) bool {
+	if setting.Field == "" {
+		var s string
+		return json.Unmarshal(value, &s) == nil && s == setting.Item
+	}
+	var fields map[string]any
+	return json.Unmarshal(value, &fields) == nil && fields[setting.Field] == setting.Item
+}
+
+// jsonMembers returns the top-level keys of the JSON object in data, in order
+func jsonMembers(data []byte) ([]jsonMember, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	if token != json.Delim('{') {
+		return nil, errors.New("expected a JSON object")
+	}
+
+	var members []jsonMember
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, fmt.Errorf("invalid value for %q: %w", token, err)
+		}
+		members = append(members, jsonMember{key: token.(string), value: value})
+	}
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// encodeJSON writes members as a JSON object with two-space indentation
+func encodeJSON(members []jsonMember) ([]byte, error) {
+	var out bytes.Buffer
+	out.WriteString("{\n")
+	for i, member := range members {
+		name, err := json.Marshal(member.key)
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString("  ")
+		out.Write(name)
+		out.WriteString(": ")
+		if err := json.Indent(&out, member.value, "  ", "  "); err != nil {
+			return nil, err
+		}
+		if i < len(members)-1 {
+			out.WriteString(",")
+		}
+		out.WriteString("\n")
+	}
+	out.WriteString("}\n")
+	return out.Bytes(), nil
+}
+
+// YAMLDocument parses the YAML mapping in data, keeping its order and
+// comments. Empty data is an empty mapping.
+func YAMLDocument(data []byte) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}, nil
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("expected a YAML mapping")
+	}
+	return &doc, nil
+}
+
+// YAMLValue returns the value of key in a mapping node, or nil
+func YAMLValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// SetYAMLValue sets key to value in a mapping node, adding it at the end
+// when the mapping doesn't have it
+func SetYAMLValue(mapping *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content[i+1] = value
+			return
+		}
+	}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
+
+// removeYAMLSettings removes settings from the YAML mapping in data
+func removeYAMLSettings(data []byte, settings []Setting) ([]byte, error) {
+	doc, err := YAMLDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	root := doc.Content[0]
+
+	for _, setting := range settings {
+		value := YAMLValue(root, setting.Key)
+		switch {
+		case value == nil:
+			continue
+		case value.Kind == yaml.SequenceNode && setting.Item != "":
+			var kept []*yaml.Node
+			for _, item := range value.Content {
+				if !yamlItemMatches(item, setting) {
+					kept = append(kept, item)
+				}
+			}
+			value.Content = kept
+			if len(kept) > 0 {
+				continue
+			}
+		case setting.Item != "" && !yamlItemMatches(value, setting):
+			continue
+		}
+		removeYAMLKey(root, setting.Key)
+	}
+
+	if len(root.Content) == 0 {
+		return nil, nil
+	}
+	return EncodeYAML(doc)
+}
+
+// yamlItemMatches reports whether a YAML node is the item of a setting
+func yamlItemMatches(node *yaml.Node, setting Setting) bool {
+	if setting.Field == "" {
+		return node.Kind == yaml.ScalarNode && node.Value == setting.Item
+	}
+	if node.Kind != yaml.MappingNode {
+		return false
+	}
+	field := YAMLValue(node, setting.Field)
+	return field != nil && field.Value == setting.Item
+}
+
+// removeYAMLKey removes key and its value from a mapping node
+func removeYAMLKey(mapping *yaml.Node, key string) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// EncodeYAML writes a YAML document with two-space indentation
+func EncodeYAML(doc *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,64 @@
+package output
+
+import "testing"
+
+func TestRemoveSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		data     string
+		settings []Setting
+		want     string
+	}{
+		{
+			name:     "JSON key",
+			path:     ".gemini/settings.json",
+			data:     "{\"theme\": \"dark\", \"contextFileName\": \"AGENTS.md\", \"mcpServers\": {}}",
+			settings: []Setting{{Key: "contextFileName", Item: "AGENTS.md"}},
+			want:     "{\n  \"theme\": \"dark\",\n  \"mcpServers\": {}\n}\n",
+		},
+		{
+			name:     "JSON key changed by hand",
+			path:     ".gemini/settings.json",
+			data:     "{\"contextFileName\": \"GEMINI.md\"}",
+			settings: []Setting{{Key: "contextFileName", Item: "AGENTS.md"}},
+			want:     "{\n  \"contextFileName\": \"GEMINI.md\"\n}\n",
+		},
+		{
+			name:     "JSON object left empty",
+			path:     ".gemini/settings.json",
+			data:     "{\"contextFileName\": \"AGENTS.md\"}",
+			settings: []Setting{{Key: "contextFileName", Item: "AGENTS.md"}},
+			want:     "",
+		},
+		{
+			name:     "YAML list item",
+			path:     ".aider.conf.yml",
+			data:     "# Aider\nread:\n  - NOTES.md\n  - CONVENTIONS.md\n",
+			settings: []Setting{{Key: "read", Item: "CONVENTIONS.md"}},
+			want:     "# Aider\nread:\n  - NOTES.md\n",
+		},
+		{
+			name: "YAML mappings by field",
+			path: ".roomodes",
+			data: "customModes:\n  - slug: my-mode\n    name: My Mode\n  - slug: api-developer\n    name: API Developer\n",
+			settings: []Setting{
+				{Key: "customModes", Item: "api-developer", Field: "slug"},
+				{Key: "customModes", Item: "ui-designer", Field: "slug"},
+			},
+			want: "customModes:\n  - slug: my-mode\n    name: My Mode\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RemoveSettings(tt.path, []byte(tt.data), tt.settings)
+			if err != nil {
+				t.Fatalf("RemoveSettings failed: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
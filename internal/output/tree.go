@@ -0,0 +1,91 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// treeNode is a directory or file in the printed tree
+type treeNode struct {
+	name     string
+	file     *File
+	children map[string]*treeNode
+}
+
+// PrintTree prints the planned files as a directory tree rooted at root,
+// with each file's size, provider and the templates it was built from
+func PrintTree(w io.Writer, p *Plan, root string) {
+	top := &treeNode{children: make(map[string]*treeNode)}
+	for i := range p.files {
+		f := &p.files[i]
+		node := top
+		parts := strings.Split(f.Path, "/")
+		for _, part := range parts[:len(parts)-1] {
+			child, ok := node.children[part]
+			if !ok {
+				child = &treeNode{name: part, children: make(map[string]*treeNode)}
+				node.children[part] = child
+			}
+			node = child
+		}
+		name := parts[len(parts)-1]
+		node.children[name] = &treeNode{name: name, file: f}
+	}
+
+	fmt.Fprintf(w, "%s/\n", strings.TrimSuffix(root, "/"))
+	printChildren(w, top, "")
+}
+
+// printChildren prints the children of a directory node, directories first
+func printChildren(w io.Writer, dir *treeNode, indent string) {
+	children := make([]*treeNode, 0, len(dir.children))
+	for _, child := range dir.children {
+		children = append(children, child)
+	}
+	sort.Slice(children, func(i, j int) bool {
+		iDir, jDir := children[i].file == nil, children[j].file == nil
+		if iDir != jDir {
+			return iDir
+		}
+		return children[i].name < children[j].name
+	})
+
+	for i, child := range children {
+		branch, next := "├── ", "│   "
+		if i == len(children)-1 {
+			branch, next = "└── ", "    "
+		}
+
+		if child.file == nil {
+			fmt.Fprintf(w, "%s%s%s/\n", indent, branch, child.name)
+			printChildren(w, child, indent+next)
+			continue
+		}
+
+		details := fmt.Sprintf("%s, %s", FormatSize(len(child.file.Content)), strings.Join(child.file.Providers(), ", "))
+		if child.file.Managed {
+			details += ", managed block"
+		}
+		if child.file.Patch != nil {
+			details += ", merged into existing settings"
+		}
+		fmt.Fprintf(w, "%s%s%s (%s)\n", indent, branch, child.name, details)
+		for _, source := range child.file.Sources {
+			fmt.Fprintf(w, "%s%s  ← %s\n", indent, next, source)
+		}
+	}
+}
+
+// FormatSize formats a byte count for display (e.g. "512 B", "3.4 KB")
+func FormatSize(n int) string {
+	switch {
+	case n < 1024:
+		return fmt.Sprintf("%d B", n)
+	case n < 1024*1024:
+		return fmt.Sprintf("%.1f KB", float64(n)/1024)
+	default:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1024*1024))
+	}
+}
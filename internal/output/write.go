@@ -0,0 +1,40 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Write writes a planned file below outputDir, creating parent directories
+// as needed, and returns the path it was written to. Managed files only
+// replace their block in an existing file, and patched files only change
+// their settings.
+func Write(outputDir string, f File) (string, error) {
+	outputPath := filepath.Join(outputDir, filepath.FromSlash(f.Path))
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory for %s: %w", f.Path, err)
+	}
+
+	var current []byte
+	if f.Managed || f.Patch != nil {
+		data, err := os.ReadFile(outputPath)
+		if err != nil && !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to read %s: %w", outputPath, err)
+		}
+		current = data
+	}
+
+	mode := f.Mode
+	if mode == 0 {
+		mode = DefaultMode
+	}
+	content, err := Render(f, current)
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(outputPath, content, mode); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", outputPath, err)
+	}
+	return outputPath, nil
+}
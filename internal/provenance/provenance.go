@@ -0,0 +1,154 @@
+// Package provenance records where generated content came from: optional
+// header comments naming the source templates, and a line-level source map
+// used by `agentspack explain`.
+package provenance
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/agentspack/agentspack/internal/output"
+)
+
+// minRun is how many consecutive lines a short line (like "---") must share
+// with a source before it is attributed to it
+const minRun = 2
+
+// Header returns a comment naming the provider, the transformation and the
+// templates a file was built from, with a short hash of each template
+func Header(f output.File, sources map[string][]byte, version string) string {
+	var sb strings.Builder
+	sb.WriteString("<!--\n")
+	sb.WriteString(fmt.Sprintf("  Generated by agentspack %s for %s", version, strings.Join(f.Providers(), ", ")))
+	if f.Transform != "" {
+		sb.WriteString(": " + f.Transform)
+	}
+	sb.WriteString("\n")
+	for _, source := range f.Sources {
+		sb.WriteString(fmt.Sprintf("  Source: %s (sha256:%s)\n", source, ShortHash(sources[source])))
+	}
+	sb.WriteString("  Edit the templates rather than this file; run 'agentspack explain' to trace a line.\n")
+	sb.WriteString("-->\n")
+	return sb.String()
+}
+
+// ShortHash returns the first 12 hex digits of the SHA-256 of content
+func ShortHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// Insert places a header at the top of markdown content, after its YAML
+// frontmatter when it has one, so tools still find the frontmatter first
+func Insert(content []byte, header string) []byte {
+	if bytes.HasPrefix(content, []byte("---\n")) {
+		if end := bytes.Index(content[4:], []byte("\n---\n")); end != -1 {
+			split := 4 + end + len("\n---\n")
+			result := append([]byte{}, content[:split]...)
+			result = append(result, header...)
+			return append(result, content[split:]...)
+		}
+	}
+	return append([]byte(header+"\n"), content...)
+}
+
+// location is a line of a source template
+type location struct {
+	source string
+	line   int // 1-based
+}
+
+// Map attributes each line of content to the template it was copied from.
+// Lines found in no template (frontmatter, headings, separators) are left
+// to the provider that added them.
+func Map(content []byte, sources []string, read map[string][]byte) []output.Span {
+	sourceLines := make(map[string][]string, len(sources))
+	index := make(map[string][]location)
+	for _, source := range sources {
+		if _, ok := sourceLines[source]; ok {
+			continue
+		}
+		lines := splitLines(read[source])
+		sourceLines[source] = lines
+		for i, line := range lines {
+			if strings.TrimSpace(line) != "" {
+				index[line] = append(index[line], location{source, i + 1})
+			}
+		}
+	}
+
+	lines := splitLines(content)
+	var spans []output.Span
+	var previous location
+	for i, line := range lines {
+		var at location
+
+		// Prefer continuing the source the previous line came from
+		if previous.source != "" {
+			next := sourceLines[previous.source]
+			if previous.line < len(next) && next[previous.line] == line {
+				at = location{previous.source, previous.line + 1}
+			}
+		}
+		if at.source == "" && strings.TrimSpace(line) != "" {
+			at = bestMatch(index[line], sourceLines, lines[i:])
+		}
+		previous = at
+
+		lineNo := i + 1
+		if n := len(spans); n > 0 {
+			last := &spans[n-1]
+			sameProvider := last.Source == "" && at.source == ""
+			continues := last.Source != "" && last.Source == at.source && at.line == last.SourceLine+lineNo-last.Start
+			if sameProvider || continues {
+				last.End = lineNo
+				continue
+			}
+		}
+		spans = append(spans, output.Span{Start: lineNo, End: lineNo, Source: at.source, SourceLine: at.line})
+	}
+	return spans
+}
+
+// bestMatch picks the candidate that shares the longest run of lines with
+// the content that follows, ignoring short lines that only match alone
+func bestMatch(candidates []location, sourceLines map[string][]string, following []string) location {
+	var best location
+	bestRun := 0
+	for _, candidate := range candidates {
+		lines := sourceLines[candidate.source]
+		run := 0
+		for run < len(following) && candidate.line-1+run < len(lines) && lines[candidate.line-1+run] == following[run] {
+			run++
+		}
+		if run > bestRun {
+			best, bestRun = candidate, run
+		}
+	}
+	if bestRun < minRun && len(strings.TrimSpace(following[0])) <= 3 {
+		return location{}
+	}
+	return best
+}
+
+// Lookup returns the span containing a line of the content
+func Lookup(spans []output.Span, line int) (output.Span, bool) {
+	for _, span := range spans {
+		if line >= span.Start && line <= span.End {
+			return span, true
+		}
+	}
+	return output.Span{}, false
+}
+
+// splitLines splits content into lines without their line endings
+func splitLines(content []byte) []string {
+	text := strings.TrimSuffix(string(content), "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
@@ -0,0 +1,52 @@
+package provenance
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/agentspack/agentspack/internal/output"
+)
+
+func TestMapAttributesLinesToSources(t *testing.T) {
+	sources := map[string][]byte{
+		"system/rules/backend/apis.md": []byte("---\nglobs: [api/**]\n---\n## APIs\n\n- Use REST\n- Version endpoints\n"),
+		"system/rules/backend/db.md":   []byte("## Databases\n\n- Use migrations\n"),
+	}
+	content := []byte("---\npaths: [api/**]\n---\n\n## APIs\n\n- Use REST\n- Version endpoints\n\n---\n\n## Databases\n\n- Use migrations\n")
+
+	spans := Map(content, []string{"system/rules/backend/apis.md", "system/rules/backend/db.md"}, sources)
+	want := []output.Span{
+		{Start: 1, End: 4},
+		{Start: 5, End: 8, Source: "system/rules/backend/apis.md", SourceLine: 4},
+		{Start: 9, End: 11},
+		{Start: 12, End: 14, Source: "system/rules/backend/db.md", SourceLine: 1},
+	}
+	if len(spans) != len(want) {
+		t.Fatalf("expected %d spans, got %+v", len(want), spans)
+	}
+	for i := range want {
+		if spans[i] != want[i] {
+			t.Errorf("span %d: expected %+v, got %+v", i, want[i], spans[i])
+		}
+	}
+
+	span, ok := Lookup(spans, 7)
+	if !ok || span.Source != "system/rules/backend/apis.md" || span.SourceLine+7-span.Start != 6 {
+		t.Fatalf("expected line 7 to come from apis.md:6, got %+v", span)
+	}
+}
+
+func TestInsertKeepsFrontmatterFirst(t *testing.T) {
+	header := Header(output.File{Provider: "codex", Sources: []string{"system/agents/a.md"}}, map[string][]byte{"system/agents/a.md": []byte("a")}, "1.2.0")
+	if !strings.Contains(header, "agentspack 1.2.0 for codex") || !strings.Contains(header, "system/agents/a.md (sha256:"+ShortHash([]byte("a"))+")") {
+		t.Fatalf("unexpected header:\n%s", header)
+	}
+
+	got := string(Insert([]byte("---\nname: a\n---\n\nBody\n"), header))
+	if !strings.HasPrefix(got, "---\nname: a\n---\n<!--") || !strings.HasSuffix(got, "-->\n\nBody\n") {
+		t.Fatalf("expected the header after the frontmatter, got:\n%s", got)
+	}
+	if got := string(Insert([]byte("# Title\n"), header)); !strings.HasPrefix(got, "<!--") || !strings.HasSuffix(got, "-->\n\n# Title\n") {
+		t.Fatalf("expected the header at the top, got:\n%s", got)
+	}
+}
@@ -0,0 +1,318 @@
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/agentspack/agentspack/internal/content"
+	"github.com/agentspack/agentspack/internal/output"
+	"github.com/agentspack/agentspack/internal/templates"
+	"github.com/agentspack/agentspack/internal/wizard"
+)
+
+func init() {
+	Register(&AiderProvider{})
+}
+
+// AiderProvider generates Aider files (CONVENTIONS.md, .aider.conf.yml and
+// reference docs)
+type AiderProvider struct{}
+
+func (p *AiderProvider) Name() string {
+	return "aider"
+}
+
+func (p *AiderProvider) DisplayName() string {
+	return "Aider"
+}
+
+func (p *AiderProvider) Description() string {
+	return "CONVENTIONS.md loaded through .aider.conf.yml, agents and workflows as docs in .aider/"
+}
+
+func (p *AiderProvider) ContentKinds() []ContentKind {
+	return []ContentKind{KindBase, KindRules, KindAgents, KindWorkflows}
+}
+
+func (p *AiderProvider) Options() []Option {
+	return nil
+}
+
+func (p *AiderProvider) OwnedDirs() []string {
+	return []string{".aider/agents", ".aider/workflows"}
+}
+
+func (p *AiderProvider) Generate(config *wizard.Config, fs content.FileSystem, plan *output.Plan) error {
+	// Aider reads its config from the home directory too, but only the
+	// repository's config lists files relative to the repository
+	if config.IsUserScope() {
+		return errors.New("Aider loads conventions through a per-repository .aider.conf.yml; generate it with the project scope")
+	}
+
+	// The .aider directory structure
+	aiderDir := ".aider"
+	agentsDir := path.Join(aiderDir, "agents")
+	workflowsDir := path.Join(aiderDir, "workflows")
+
+	// 0. Generate agents and workflows as docs to add with /read, since
+	// Aider has neither
+	agents, err := p.generateAgentDocs(fs, plan, agentsDir, config)
+	if err != nil {
+		return fmt.Errorf("failed to generate agent docs: %w", err)
+	}
+	workflows, err := p.generateWorkflowDocs(fs, plan, workflowsDir, config)
+	if err != nil {
+		return fmt.Errorf("failed to generate workflow docs: %w", err)
+	}
+
+	// 1. Generate CONVENTIONS.md with base content, rules and an index of
+	// the docs
+	written, err := p.generateConventions(fs, plan, agents, workflows, config)
+	if err != nil {
+		return fmt.Errorf("failed to generate CONVENTIONS.md: %w", err)
+	}
+	if !written {
+		return nil
+	}
+
+	// 2. Generate .aider.conf.yml so every chat loads CONVENTIONS.md,
+	// keeping the project's own settings
+	plan.Add(output.File{
+		Path:      ".aider.conf.yml",
+		Content:   []byte("# Load the project conventions into every chat, read-only\nread:\n  - " + aiderConventions + "\n"),
+		Transform: "Aider config reading CONVENTIONS.md",
+		Provider:  p.Name(),
+		Patch:     readConventions,
+		Settings:  []output.Setting{{Key: "read", Item: aiderConventions}},
+	})
+	return nil
+}
+
+// aiderConventions is the conventions file .aider.conf.yml reads
+const aiderConventions = "CONVENTIONS.md"
+
+// readConventions adds CONVENTIONS.md to the read list of an Aider config
+// unless it is there already, keeping the other settings and files
+func readConventions(config []byte) ([]byte, error) {
+	doc, err := output.YAMLDocument(config)
+	if err != nil {
+		return nil, err
+	}
+	root := doc.Content[0]
+	conventions := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: aiderConventions}
+
+	read := output.YAMLValue(root, "read")
+	switch {
+	case read == nil || read.Tag == "!!null":
+		output.SetYAMLValue(root, "read", &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: []*yaml.Node{conventions}})
+	case read.Kind == yaml.ScalarNode:
+		// A single file, which Aider also accepts
+		if read.Value == aiderConventions {
+			return config, nil
+		}
+		output.SetYAMLValue(root, "read", &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: []*yaml.Node{read, conventions}})
+	case read.Kind == yaml.SequenceNode:
+		for _, item := range read.Content {
+			if item.Value == aiderConventions {
+				return config, nil
+			}
+		}
+		read.Content = append(read.Content, conventions)
+	default:
+		return nil, errors.New("read is not a file or a list of files")
+	}
+	return output.EncodeYAML(doc)
+}
+
+// aiderDoc is an agent or workflow written as a doc, listed in CONVENTIONS.md
+type aiderDoc struct {
+	Path        string
+	Title       string
+	Description string
+}
+
+// generateConventions creates CONVENTIONS.md from base.md + Aider.md, the
+// global rules, the tech stack rules and an index of the agent and workflow
+// docs. It reports whether there was anything to write.
+func (p *AiderProvider) generateConventions(fs content.FileSystem, plan *output.Plan, agents, workflows []aiderDoc, config *wizard.Config) (bool, error) {
+	var contentBuilder strings.Builder
+	var sources []string
+	section := func() {
+		if contentBuilder.Len() > 0 {
+			contentBuilder.WriteString("\n\n---\n\n")
+		}
+	}
+
+	if config.GenerateBase {
+		baseContent, err := fs.ReadFile("system/base/base.md")
+		if err != nil {
+			return false, fmt.Errorf("failed to read base.md: %w", err)
+		}
+		providerContent, err := fs.ReadFile("system/base/Aider.md")
+		if err != nil {
+			return false, fmt.Errorf("failed to read Aider.md: %w", err)
+		}
+
+		contentBuilder.Write(baseContent)
+		contentBuilder.WriteString("\n\n")
+		contentBuilder.Write(providerContent)
+		sources = append(sources, "system/base/base.md", "system/base/Aider.md")
+	}
+
+	rules, err := loadRules(fs, "global", p.Name(), config)
+	if err != nil {
+		return false, err
+	}
+	if len(rules) > 0 {
+		section()
+		contentBuilder.WriteString("# Project Guidelines\n\n")
+		contentBuilder.WriteString("These guidelines apply to all work in this project.\n\n")
+		contentBuilder.WriteString(joinRules(rules, true))
+		sources = append(sources, rulePaths(rules)...)
+	}
+
+	// Aider loads CONVENTIONS.md whole, so each stack's rules are a
+	// section that names the files they are meant for
+	selected, err := selectedStacks(config, fs)
+	if err != nil {
+		return false, err
+	}
+	stackRuleCount := 0
+	for _, stack := range selected {
+		stackRules, err := loadRules(fs, stack.SourcePath, p.Name(), config)
+		if err != nil {
+			return false, fmt.Errorf("failed to load %s rules: %w", stack.Name, err)
+		}
+		if len(stackRules) == 0 {
+			continue
+		}
+		stackRuleCount += len(stackRules)
+
+		section()
+		contentBuilder.WriteString(fmt.Sprintf("# %s Guidelines\n\n", stack.DisplayName))
+		if globs := resolveStackGlobs(config, stack.Name, stack.Globs); len(globs) > 0 {
+			contentBuilder.WriteString(fmt.Sprintf("These guidelines apply when working on files matching `%s`.\n\n", strings.Join(globs, "`, `")))
+		}
+		contentBuilder.WriteString(joinRules(stackRules, true))
+		sources = append(sources, rulePaths(stackRules)...)
+	}
+	if stackRuleCount > 0 {
+		plan.Warn(p.Name(), fmt.Sprintf("Aider can't scope rules to paths, so %d tech stack rules are always loaded from CONVENTIONS.md", stackRuleCount))
+	}
+
+	for _, index := range []struct {
+		title string
+		docs  []aiderDoc
+	}{
+		{"Agents", agents},
+		{"Workflows", workflows},
+	} {
+		if len(index.docs) == 0 {
+			continue
+		}
+		section()
+		contentBuilder.WriteString(fmt.Sprintf("# %s\n\n", index.title))
+		contentBuilder.WriteString("Add one to the chat with `/read <path>` when the task calls for it.\n\n")
+		for _, doc := range index.docs {
+			contentBuilder.WriteString(fmt.Sprintf("- `%s` — **%s**", doc.Path, doc.Title))
+			if doc.Description != "" {
+				contentBuilder.WriteString(": " + doc.Description)
+			}
+			contentBuilder.WriteString("\n")
+		}
+	}
+
+	if contentBuilder.Len() == 0 {
+		return false, nil
+	}
+
+	// Plan CONVENTIONS.md as a managed block that keeps the project's own
+	// conventions around it
+	plan.Add(output.File{
+		Path:      "CONVENTIONS.md",
+		Content:   []byte(contentBuilder.String()),
+		Sources:   sources,
+		Transform: "base.md, Aider.md, global and tech stack rules concatenated, with an index of the docs in .aider/",
+		Provider:  p.Name(),
+		Managed:   true,
+	})
+	return true, nil
+}
+
+// generateAgentDocs writes each agent as a doc in agentsDir and returns
+// them for the index
+func (p *AiderProvider) generateAgentDocs(fs content.FileSystem, plan *output.Plan, agentsDir string, config *wizard.Config) ([]aiderDoc, error) {
+	agents, err := loadAgents(fs, config)
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []aiderDoc
+	for _, agent := range agents {
+		doc := aiderDoc{
+			Path:        path.Join(agentsDir, agent.Name+".md"),
+			Title:       templates.NormalizeWorkflowName(agent.Name),
+			Description: agent.Description,
+		}
+		plan.Add(output.File{
+			Path:      doc.Path,
+			Content:   []byte(fmt.Sprintf("# %s\n\n%s\n", doc.Title, agent.Body)),
+			Sources:   []string{agent.Path},
+			Transform: "agent as a reference doc",
+			Provider:  p.Name(),
+		})
+		docs = append(docs, doc)
+	}
+	if len(docs) > 0 {
+		plan.Warn(p.Name(), fmt.Sprintf("Aider has no agents, so %d agents were written as docs in %s/ to add with /read", len(docs), agentsDir))
+	}
+	return docs, nil
+}
+
+// generateWorkflowDocs writes each workflow step as a doc in workflowsDir,
+// plus an orchestrator doc that says which step doc to add next, and
+// returns the orchestrators for the index
+func (p *AiderProvider) generateWorkflowDocs(fs content.FileSystem, plan *output.Plan, workflowsDir string, config *wizard.Config) ([]aiderDoc, error) {
+	workflows, err := loadWorkflows(fs, config)
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []aiderDoc
+	for _, workflow := range workflows {
+		stepPath := func(step Step) string {
+			return path.Join(workflowsDir, step.Command+".md")
+		}
+		for _, step := range workflow.Steps {
+			plan.Add(output.File{
+				Path:      stepPath(step),
+				Content:   step.Content,
+				Sources:   []string{step.Path},
+				Transform: "workflow step copied as a reference doc",
+				Provider:  p.Name(),
+			})
+		}
+
+		doc := aiderDoc{
+			Path:        path.Join(workflowsDir, workflow.Name+".md"),
+			Title:       templates.NormalizeWorkflowName(workflow.Name),
+			Description: fmt.Sprintf("%d steps, each in its own doc", len(workflow.Steps)),
+		}
+		plan.Add(output.File{
+			Path:      doc.Path,
+			Content:   []byte(workflow.Orchestrator("/read ", stepPath)),
+			Sources:   workflow.Sources(),
+			Transform: "workflow orchestrator listing the step docs to add with /read",
+			Provider:  p.Name(),
+		})
+		docs = append(docs, doc)
+	}
+	if len(docs) > 0 {
+		plan.Warn(p.Name(), fmt.Sprintf("Aider has no custom commands, so %d workflows were written as docs in %s/ to add with /read", len(docs), workflowsDir))
+	}
+	return docs, nil
+}
@@ -0,0 +1,107 @@
+package providers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/agentspack/agentspack/internal/output"
+	"github.com/agentspack/agentspack/internal/wizard"
+)
+
+func TestAiderGenerate(t *testing.T) {
+	config := &wizard.Config{Providers: []string{"aider"}, TechStacks: []string{"backend"}, GenerateBase: true}
+	plan := output.NewPlan()
+	if err := (&AiderProvider{}).Generate(config, writeTestTemplates(t), plan); err != nil {
+		t.Fatalf("Failed to generate: %v", err)
+	}
+
+	expected := map[string]string{
+		"CONVENTIONS.md":                              "# Backend Guidelines\n",
+		".aider.conf.yml":                             "read:\n  - CONVENTIONS.md\n",
+		".aider/agents/ui-designer.md":                "# Ui Designer\n\nYou design interfaces.\n",
+		".aider/agents/api-developer.md":              "You build APIs.\n",
+		".aider/workflows/planning-01-write-prd.md":   "# PRD\n",
+		".aider/workflows/planning-02-write-todos.md": "# Todos\n",
+		".aider/workflows/planning.md":                "**Invoke**: /read .aider/workflows/planning-02-write-todos.md\n",
+	}
+	for path, want := range expected {
+		f, ok := plan.Get(path)
+		if !ok {
+			t.Errorf("Expected %s to be planned", path)
+			continue
+		}
+		if !strings.Contains(string(f.Content), want) {
+			t.Errorf("Expected %s to contain %q, got:\n%s", path, want, f.Content)
+		}
+	}
+
+	conventions, _ := plan.Get("CONVENTIONS.md")
+	for _, want := range []string{"# Aider\n", "## Coding style\n", "- `.aider/agents/ui-designer.md` — **Ui Designer**: Designs interfaces\n", "- `.aider/workflows/planning.md`"} {
+		if !strings.Contains(string(conventions.Content), want) {
+			t.Errorf("Expected CONVENTIONS.md to contain %q, got:\n%s", want, conventions.Content)
+		}
+	}
+
+	// Agents, workflows and tech stack rules are each reported as degraded
+	if len(plan.Warnings()) != 3 {
+		t.Errorf("Expected 3 warnings, got %v", plan.Warnings())
+	}
+
+	config.Scope = wizard.ScopeUser
+	if err := (&AiderProvider{}).Generate(config, writeTestTemplates(t), output.NewPlan()); err == nil {
+		t.Error("Expected the user scope to be rejected")
+	}
+}
+
+func TestAiderConfigKeepsSettings(t *testing.T) {
+	config := &wizard.Config{Providers: []string{"aider"}, GenerateBase: true}
+	plan := output.NewPlan()
+	if err := (&AiderProvider{}).Generate(config, writeTestTemplates(t), plan); err != nil {
+		t.Fatalf("Failed to generate: %v", err)
+	}
+	f, ok := plan.Get(".aider.conf.yml")
+	if !ok {
+		t.Fatal("Expected .aider.conf.yml to be planned")
+	}
+
+	tests := []struct {
+		current string
+		want    string
+	}{
+		{
+			"model: sonnet\nauto-commits: false # review first\nread: [docs/ARCH.md]\n",
+			"model: sonnet\nauto-commits: false # review first\nread: [docs/ARCH.md, CONVENTIONS.md]\n",
+		},
+		{
+			"model: sonnet\nread:\n  - docs/ARCH.md\n",
+			"model: sonnet\nread:\n  - docs/ARCH.md\n  - CONVENTIONS.md\n",
+		},
+		{
+			"read: docs/ARCH.md\n",
+			"read:\n  - docs/ARCH.md\n  - CONVENTIONS.md\n",
+		},
+		{
+			"model: sonnet\n",
+			"model: sonnet\nread:\n  - CONVENTIONS.md\n",
+		},
+		// Already read, so the file is left as it is
+		{
+			"read:   [CONVENTIONS.md,   docs/ARCH.md]\n",
+			"read:   [CONVENTIONS.md,   docs/ARCH.md]\n",
+		},
+	}
+	for _, tt := range tests {
+		got, err := output.Render(f, []byte(tt.current))
+		if err != nil {
+			t.Errorf("Failed to update %q: %v", tt.current, err)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("Expected %q to become %q, got %q", tt.current, tt.want, got)
+		}
+	}
+
+	if _, err := output.Render(f, []byte("read: {docs: ARCH.md}\n")); err == nil {
+		t.Error("Expected a read setting that isn't a file or a list to be rejected")
+	}
+}
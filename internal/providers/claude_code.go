@@ -2,14 +2,12 @@ package providers
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
-	"regexp"
-	"sort"
-	"strconv"
+	"path"
 	"strings"
 
 	"github.com/agentspack/agentspack/internal/content"
+	"github.com/agentspack/agentspack/internal/output"
+	"github.com/agentspack/agentspack/internal/stacks"
 	"github.com/agentspack/agentspack/internal/templates"
 	"github.com/agentspack/agentspack/internal/wizard"
 )
@@ -25,98 +23,92 @@ func (p *ClaudeCodeProvider) Name() string {
 	return "claude-code"
 }
 
-// claudeCodeStackConfigs maps wizard tech stack choices to their configurations
-var claudeCodeStackConfigs = map[string]struct {
-	SourcePath       string
-	Name             string
-	Globs            []string // For rules mode (path scoping)
-	Description      string   // For skills mode
-	ShortDescription string
-}{
-	"react": {
-		SourcePath:       "frontend/react",
-		Name:             "react",
-		Globs:            []string{"**/*.tsx", "**/*.jsx", "src/components/**", "src/pages/**", "src/app/**"},
-		Description:      "Best practices for React development. Use when building React components, managing state with hooks, creating reusable UI elements, or working with JSX/TSX files.",
-		ShortDescription: "React component and UI development guidelines",
-	},
-	"backend": {
-		SourcePath:       "backend",
-		Name:             "backend",
-		Globs:            []string{"**/*.go", "**/*.py", "src/api/**", "src/server/**", "api/**", "server/**"},
-		Description:      "Best practices for backend development. Use when building APIs, working with databases, designing data models, implementing authentication, or writing server-side logic.",
-		ShortDescription: "Backend API and database development guidelines",
-	},
+func (p *ClaudeCodeProvider) DisplayName() string {
+	return "Claude Code"
 }
 
-func (p *ClaudeCodeProvider) Generate(config *wizard.Config, fs content.FileSystem, outputDir string) error {
-	// Create the .claude directory structure
-	claudeDir := filepath.Join(outputDir, ".claude")
-	rulesDir := filepath.Join(claudeDir, "rules")
-	agentsDir := filepath.Join(claudeDir, "agents")
-	commandsDir := filepath.Join(claudeDir, "commands")
-	skillsDir := filepath.Join(claudeDir, "skills")
-
-	// Create necessary directories
-	if err := os.MkdirAll(rulesDir, 0755); err != nil {
-		return fmt.Errorf("failed to create claude rules directory: %w", err)
+func (p *ClaudeCodeProvider) Description() string {
+	return "CLAUDE.md, rules or skills, sub-agents and slash commands in .claude/"
+}
+
+func (p *ClaudeCodeProvider) ContentKinds() []ContentKind {
+	return []ContentKind{KindBase, KindRules, KindSkills, KindAgents, KindWorkflows}
+}
+
+func (p *ClaudeCodeProvider) Options() []Option {
+	return []Option{
+		{
+			Key:         "claude_code_mode",
+			Title:       "Claude Code: How should tech stack guidelines be generated?",
+			Description: "Rules are always loaded; Skills are loaded on-demand when relevant",
+			Choices: []Choice{
+				{Value: string(wizard.ClaudeCodeModeRules), Label: "Rule files (always loaded, path-scoped)"},
+				{Value: string(wizard.ClaudeCodeModeSkills), Label: "Skills (loaded on-demand by Claude)"},
+			},
+			Get: func(config *wizard.Config) string { return string(config.ClaudeCodeMode) },
+			Set: func(config *wizard.Config, value string) { config.ClaudeCodeMode = wizard.ClaudeCodeMode(value) },
+		},
 	}
+}
 
-	// 0. Generate CLAUDE.md base file if requested
+func (p *ClaudeCodeProvider) OwnedDirs() []string {
+	return []string{".claude/rules", ".claude/agents", ".claude/commands", ".claude/skills"}
+}
+
+func (p *ClaudeCodeProvider) Generate(config *wizard.Config, fs content.FileSystem, plan *output.Plan) error {
+	// The .claude directory structure
+	claudeDir := ".claude"
+	rulesDir := path.Join(claudeDir, "rules")
+	agentsDir := path.Join(claudeDir, "agents")
+	commandsDir := path.Join(claudeDir, "commands")
+	skillsDir := path.Join(claudeDir, "skills")
+
+	// 0. Generate CLAUDE.md base file if requested. Personal instructions
+	// live in ~/.claude/CLAUDE.md.
+	basePath := "CLAUDE.md"
+	if config.IsUserScope() {
+		basePath = path.Join(claudeDir, "CLAUDE.md")
+	}
 	if config.GenerateBase {
-		if err := p.generateBaseFile(fs, outputDir); err != nil {
+		if err := p.generateBaseFile(fs, plan, basePath); err != nil {
 			return fmt.Errorf("failed to generate CLAUDE.md: %w", err)
 		}
 	}
 
 	// 1. Generate global rules (always as a rule file)
-	if err := p.generateGlobalRules(fs, rulesDir); err != nil {
+	if err := p.generateGlobalRules(fs, plan, rulesDir, config); err != nil {
 		return fmt.Errorf("failed to generate global rules: %w", err)
 	}
 
 	// 2. Generate tech stack content based on user's mode choice
+	selected, err := selectedStacks(config, fs)
+	if err != nil {
+		return err
+	}
 	if config.ClaudeCodeMode == wizard.ClaudeCodeModeSkills {
 		// Skills mode: generate skills for tech stacks
-		if err := os.MkdirAll(skillsDir, 0755); err != nil {
-			return fmt.Errorf("failed to create skills directory: %w", err)
-		}
-		for _, stack := range config.TechStacks {
-			stackConfig, ok := claudeCodeStackConfigs[stack]
-			if !ok {
-				fmt.Printf("Warning: no configuration for tech stack '%s', skipping\n", stack)
-				continue
-			}
-			if err := p.generateStackSkill(fs, skillsDir, stack, stackConfig); err != nil {
-				return fmt.Errorf("failed to generate %s skill: %w", stack, err)
+		for _, stack := range selected {
+			if err := p.generateStackSkill(fs, plan, skillsDir, rulesDir, stack, config); err != nil {
+				return fmt.Errorf("failed to generate %s skill: %w", stack.Name, err)
 			}
 		}
 	} else {
 		// Rules mode: generate rule files with path scoping
-		for _, stack := range config.TechStacks {
-			stackConfig, ok := claudeCodeStackConfigs[stack]
-			if !ok {
-				fmt.Printf("Warning: no configuration for tech stack '%s', skipping\n", stack)
-				continue
-			}
-			if err := p.generateStackRules(fs, rulesDir, stack, stackConfig); err != nil {
-				return fmt.Errorf("failed to generate %s rules: %w", stack, err)
+		for _, stack := range selected {
+			stack.Globs = resolveStackGlobs(config, stack.Name, stack.Globs)
+			if err := p.generateStackRules(fs, plan, rulesDir, stack, config); err != nil {
+				return fmt.Errorf("failed to generate %s rules: %w", stack.Name, err)
 			}
 		}
 	}
 
 	// 3. Generate agents as sub-agents
-	if err := os.MkdirAll(agentsDir, 0755); err != nil {
-		return fmt.Errorf("failed to create agents directory: %w", err)
-	}
-	if err := p.generateSubAgents(fs, agentsDir); err != nil {
+	if err := p.generateSubAgents(fs, plan, agentsDir, config); err != nil {
 		return fmt.Errorf("failed to generate sub-agents: %w", err)
 	}
 
 	// 4. Generate workflows as slash commands
-	if err := os.MkdirAll(commandsDir, 0755); err != nil {
-		return fmt.Errorf("failed to create commands directory: %w", err)
-	}
-	if err := p.generateWorkflowCommands(fs, commandsDir); err != nil {
+	if err := p.generateWorkflowCommands(fs, plan, commandsDir, config); err != nil {
 		return fmt.Errorf("failed to generate workflow commands: %w", err)
 	}
 
@@ -124,7 +116,7 @@ func (p *ClaudeCodeProvider) Generate(config *wizard.Config, fs content.FileSyst
 }
 
 // generateBaseFile creates the CLAUDE.md file from base.md + Claude.md
-func (p *ClaudeCodeProvider) generateBaseFile(fs content.FileSystem, outputDir string) error {
+func (p *ClaudeCodeProvider) generateBaseFile(fs content.FileSystem, plan *output.Plan, basePath string) error {
 	// Read base.md
 	baseContent, err := fs.ReadFile("system/base/base.md")
 	if err != nil {
@@ -143,417 +135,249 @@ func (p *ClaudeCodeProvider) generateBaseFile(fs content.FileSystem, outputDir s
 	contentBuilder.WriteString("\n\n")
 	contentBuilder.Write(providerContent)
 
-	// Write CLAUDE.md to output directory root
-	outputPath := filepath.Join(outputDir, "CLAUDE.md")
-	if err := os.WriteFile(outputPath, []byte(contentBuilder.String()), 0644); err != nil {
-		return fmt.Errorf("failed to write CLAUDE.md: %w", err)
-	}
-
-	fmt.Printf("  Created: %s\n", outputPath)
+	// Plan CLAUDE.md as a managed block that keeps the notes around it.
+	// With dedupe, it imports AGENTS.md rather than repeating base.md.
+	plan.Add(output.File{
+		Path:      basePath,
+		Content:   []byte(contentBuilder.String()),
+		Sources:   []string{"system/base/base.md", "system/base/Claude.md"},
+		Transform: "base.md and Claude.md concatenated",
+		Provider:  p.Name(),
+		Managed:   true,
+		Import:    "@%s",
+	})
 	return nil
 }
 
-// generateGlobalRules creates a single global.md rule file with all global rules
-func (p *ClaudeCodeProvider) generateGlobalRules(fs content.FileSystem, rulesDir string) error {
-	// Find all markdown files in global directory
-	files, err := fs.Glob("system/rules/global/*.md")
+// generateGlobalRules creates a single global.md rule file with all global
+// rules, plus a separate rule file for each global rule with its own scope
+func (p *ClaudeCodeProvider) generateGlobalRules(fs content.FileSystem, plan *output.Plan, rulesDir string, config *wizard.Config) error {
+	rules, err := loadRules(fs, "global", p.Name(), config)
 	if err != nil {
 		return err
 	}
 
-	if len(files) == 0 {
-		return fmt.Errorf("no global rule files found")
+	var merged []Rule
+	for _, rule := range rules {
+		if !rule.Scoped() {
+			merged = append(merged, rule)
+			continue
+		}
+		ruleName := "global-" + rule.Name
+		p.writeRule(plan, rulesDir, ruleName, rule.DescriptionOr("Global", ruleName), rule.IsAlwaysApply(len(rule.Globs) == 0), rule.Globs, rule.Body, []string{rule.Path})
+	}
+
+	if len(merged) == 0 {
+		return nil
 	}
 
 	// Concatenate all files
 	var contentBuilder strings.Builder
-
-	// Write frontmatter for Claude Code rules
-	contentBuilder.WriteString("---\n")
-	contentBuilder.WriteString("description: Global coding standards and best practices that apply to all files\n")
-	contentBuilder.WriteString("alwaysApply: true\n")
-	contentBuilder.WriteString("---\n\n")
-
 	contentBuilder.WriteString("# Global Coding Standards\n\n")
 	contentBuilder.WriteString("These rules apply to all files in the project.\n\n")
+	contentBuilder.WriteString(joinRules(merged, false))
 
-	for i, file := range files {
-		fileContent, err := fs.ReadFile(file)
-		if err != nil {
-			return fmt.Errorf("failed to read %s: %w", file, err)
-		}
-
-		if i > 0 {
-			contentBuilder.WriteString("\n---\n\n")
-		}
-
-		contentBuilder.Write(fileContent)
-		contentBuilder.WriteString("\n")
-	}
-
-	// Write global.md
-	outputPath := filepath.Join(rulesDir, "global.md")
-	if err := os.WriteFile(outputPath, []byte(contentBuilder.String()), 0644); err != nil {
-		return fmt.Errorf("failed to write %s: %w", outputPath, err)
-	}
-
-	fmt.Printf("  Created: %s\n", outputPath)
+	p.writeRule(plan, rulesDir, "global", "Global coding standards and best practices that apply to all files", true, nil, contentBuilder.String(), rulePaths(merged))
 	return nil
 }
 
-// generateStackRules creates rule files with path scoping for tech stacks
-func (p *ClaudeCodeProvider) generateStackRules(fs content.FileSystem, rulesDir, stackName string, config struct {
-	SourcePath       string
-	Name             string
-	Globs            []string
-	Description      string
-	ShortDescription string
-}) error {
-	// Find all markdown files in the stack directory
-	pattern := fmt.Sprintf("system/rules/%s/*.md", config.SourcePath)
-	files, err := fs.Glob(pattern)
+// generateStackRules creates rule files with path scoping for tech stacks.
+// Rules sharing the stack's scope are combined into <stack>.md; rules with
+// their own globs or alwaysApply get a file of their own.
+func (p *ClaudeCodeProvider) generateStackRules(fs content.FileSystem, plan *output.Plan, rulesDir string, stack stacks.Stack, config *wizard.Config) error {
+	rules, err := loadRules(fs, stack.SourcePath, p.Name(), config)
 	if err != nil {
 		return err
 	}
 
-	// Also check subdirectories
-	subPattern := fmt.Sprintf("system/rules/%s/**/*.md", config.SourcePath)
-	subFiles, err := fs.Glob(subPattern)
-	if err == nil {
-		files = append(files, subFiles...)
+	var merged []Rule
+	for _, rule := range rules {
+		if !rule.Scoped() {
+			merged = append(merged, rule)
+			continue
+		}
+		p.writeScopedStackRule(plan, rulesDir, rule, stack)
 	}
 
-	if len(files) == 0 {
+	if len(merged) == 0 {
 		return nil
 	}
 
-	// Collect all content
-	var allContent strings.Builder
-	for i, file := range files {
-		fileContent, err := fs.ReadFile(file)
-		if err != nil {
-			return err
-		}
+	var body strings.Builder
+	body.WriteString(fmt.Sprintf("# %s Guidelines\n\n", stack.DisplayName))
+	body.WriteString(joinRules(merged, false))
 
-		if i > 0 {
-			allContent.WriteString("\n---\n\n")
-		}
+	p.writeRule(plan, rulesDir, stack.Name, fmt.Sprintf("%s development guidelines", stack.DisplayName), false, stack.Globs, body.String(), rulePaths(merged))
+	return nil
+}
+
+// writeScopedStackRule plans a stack rule with its own scope at <stack>-<rule>.md
+func (p *ClaudeCodeProvider) writeScopedStackRule(plan *output.Plan, rulesDir string, rule Rule, stack stacks.Stack) {
+	ruleName := fmt.Sprintf("%s-%s", stack.Name, rule.Name)
 
-		allContent.Write(fileContent)
-		allContent.WriteString("\n")
+	globs := stack.Globs
+	if len(rule.Globs) > 0 {
+		globs = rule.Globs
 	}
 
-	// Build rule content with frontmatter
+	p.writeRule(plan, rulesDir, ruleName, rule.DescriptionOr(stack.RuleDescription, ruleName), rule.IsAlwaysApply(false), globs, rule.Body, []string{rule.Path})
+}
+
+// writeRule plans a Claude Code rule file at <rulesDir>/<ruleName>.md
+func (p *ClaudeCodeProvider) writeRule(plan *output.Plan, rulesDir, ruleName, description string, alwaysApply bool, globs []string, body string, sources []string) {
 	var ruleContent strings.Builder
 
 	ruleContent.WriteString("---\n")
-	ruleContent.WriteString(fmt.Sprintf("description: %s development guidelines\n", templates.NormalizeWorkflowName(stackName)))
-	ruleContent.WriteString("alwaysApply: false\n")
-	ruleContent.WriteString("globs:\n")
-	for _, glob := range config.Globs {
-		ruleContent.WriteString(fmt.Sprintf("  - %s\n", glob))
+	ruleContent.WriteString(fmt.Sprintf("description: \"%s\"\n", escapeYAMLString(description)))
+	ruleContent.WriteString(fmt.Sprintf("alwaysApply: %t\n", alwaysApply))
+	if len(globs) > 0 {
+		ruleContent.WriteString("globs:\n")
+		for _, glob := range globs {
+			ruleContent.WriteString(fmt.Sprintf("  - %s\n", glob))
+		}
 	}
 	ruleContent.WriteString("---\n\n")
-
-	ruleContent.WriteString(fmt.Sprintf("# %s Guidelines\n\n", templates.NormalizeWorkflowName(stackName)))
-	ruleContent.WriteString(allContent.String())
-
-	// Write the rule file
-	outputPath := filepath.Join(rulesDir, fmt.Sprintf("%s.md", config.Name))
-	if err := os.WriteFile(outputPath, []byte(ruleContent.String()), 0644); err != nil {
-		return err
-	}
-
-	fmt.Printf("  Created: %s\n", outputPath)
-	return nil
+	ruleContent.WriteString(body)
+
+	plan.Add(output.File{
+		Path:      path.Join(rulesDir, ruleName+".md"),
+		Content:   []byte(ruleContent.String()),
+		Sources:   sources,
+		Transform: "rules joined into a rule file with frontmatter",
+		Provider:  p.Name(),
+		Covered:   alwaysApply,
+	})
 }
 
-// generateStackSkill creates a skill for a tech stack with good metadata
-func (p *ClaudeCodeProvider) generateStackSkill(fs content.FileSystem, skillsDir, stackName string, config struct {
-	SourcePath       string
-	Name             string
-	Globs            []string
-	Description      string
-	ShortDescription string
-}) error {
-	// Create skill directory
-	skillDir := filepath.Join(skillsDir, fmt.Sprintf("%s-guidelines", config.Name))
-	if err := os.MkdirAll(skillDir, 0755); err != nil {
-		return err
-	}
-
-	// Find all markdown files in the stack directory
-	pattern := fmt.Sprintf("system/rules/%s/*.md", config.SourcePath)
-	files, err := fs.Glob(pattern)
+// generateStackSkill creates a skill for a tech stack with good metadata.
+// Rules marked alwaysApply are written as rule files instead, since skills
+// are only loaded on demand.
+func (p *ClaudeCodeProvider) generateStackSkill(fs content.FileSystem, plan *output.Plan, skillsDir, rulesDir string, stack stacks.Stack, config *wizard.Config) error {
+	rules, err := loadRules(fs, stack.SourcePath, p.Name(), config)
 	if err != nil {
 		return err
 	}
 
-	// Also check subdirectories
-	subPattern := fmt.Sprintf("system/rules/%s/**/*.md", config.SourcePath)
-	subFiles, err := fs.Glob(subPattern)
-	if err == nil {
-		files = append(files, subFiles...)
+	var skillRules []Rule
+	for _, rule := range rules {
+		if rule.IsAlwaysApply(false) {
+			p.writeScopedStackRule(plan, rulesDir, rule, stack)
+			continue
+		}
+		skillRules = append(skillRules, rule)
 	}
 
-	if len(files) == 0 {
+	if len(skillRules) == 0 {
 		return nil
 	}
 
-	// Collect all content
-	var allContent strings.Builder
-	for i, file := range files {
-		fileContent, err := fs.ReadFile(file)
-		if err != nil {
-			return err
-		}
-
-		if i > 0 {
-			allContent.WriteString("\n---\n\n")
-		}
-
-		allContent.Write(fileContent)
-		allContent.WriteString("\n")
-	}
-
 	// Build SKILL.md content with frontmatter
 	var skillContent strings.Builder
 
 	skillContent.WriteString("---\n")
-	skillContent.WriteString(fmt.Sprintf("name: %s-guidelines\n", config.Name))
-	skillContent.WriteString(fmt.Sprintf("description: %s\n", config.Description))
+	skillContent.WriteString(fmt.Sprintf("name: %s\n", stack.SkillName()))
+	skillContent.WriteString(fmt.Sprintf("description: \"%s\"\n", escapeYAMLString(stack.SkillDescription)))
 	skillContent.WriteString("---\n\n")
 
-	skillContent.WriteString(fmt.Sprintf("# %s Guidelines\n\n", templates.NormalizeWorkflowName(stackName)))
-	skillContent.WriteString(allContent.String())
-
-	// Write SKILL.md
-	outputPath := filepath.Join(skillDir, "SKILL.md")
-	if err := os.WriteFile(outputPath, []byte(skillContent.String()), 0644); err != nil {
-		return err
-	}
+	skillContent.WriteString(fmt.Sprintf("# %s Guidelines\n\n", stack.DisplayName))
+	skillContent.WriteString(joinRules(skillRules, true))
 
-	fmt.Printf("  Created: %s\n", outputPath)
+	// Plan SKILL.md in the skill's directory
+	plan.Add(output.File{
+		Path:      path.Join(skillsDir, stack.SkillName(), "SKILL.md"),
+		Content:   []byte(skillContent.String()),
+		Sources:   rulePaths(skillRules),
+		Transform: "stack rules joined into a skill with frontmatter",
+		Provider:  p.Name(),
+	})
 	return nil
 }
 
 // generateSubAgents creates sub-agent files from agent templates
-func (p *ClaudeCodeProvider) generateSubAgents(fs content.FileSystem, agentsDir string) error {
-	// Check if agents directory exists
-	if _, err := fs.Stat("system/agents"); err != nil {
-		return nil
-	}
-
-	// Find all markdown files in the agents directory
-	files, err := fs.Glob("system/agents/*.md")
+func (p *ClaudeCodeProvider) generateSubAgents(fs content.FileSystem, plan *output.Plan, agentsDir string, config *wizard.Config) error {
+	agents, err := loadAgents(fs, config)
 	if err != nil {
 		return err
 	}
 
-	// Also check subdirectories (e.g., system/agents/backend/*.md)
-	subFiles, err := fs.Glob("system/agents/**/*.md")
-	if err == nil {
-		files = append(files, subFiles...)
-	}
-
-	for _, file := range files {
-		if err := p.createSubAgent(fs, file, agentsDir); err != nil {
-			return err
-		}
+	for _, agent := range agents {
+		p.createSubAgent(plan, agent, agentsDir)
 	}
-
 	return nil
 }
 
-// createSubAgent creates a Claude Code sub-agent from an agent markdown file
-func (p *ClaudeCodeProvider) createSubAgent(fs content.FileSystem, sourcePath, agentsDir string) error {
-	fileContent, err := fs.ReadFile(sourcePath)
-	if err != nil {
-		return err
-	}
-
-	contentStr := string(fileContent)
-
-	// Parse frontmatter to extract agent metadata
-	agentName, description, bodyContent := parseAgentFrontmatter(contentStr)
-
-	// Generate agent name from filename if not in frontmatter
-	if agentName == "" {
-		baseName := filepath.Base(sourcePath)
-		agentName = strings.TrimSuffix(baseName, ".md")
+// createSubAgent creates a Claude Code sub-agent from an agent template
+func (p *ClaudeCodeProvider) createSubAgent(plan *output.Plan, agent Agent, agentsDir string) {
+	// Use extracted description or generate one
+	description := agent.Description
+	if description == "" {
+		description = fmt.Sprintf("Specialized agent for %s tasks", templates.NormalizeWorkflowName(agent.Name))
 	}
 
-	// Normalize the name (replace underscores with hyphens)
-	agentName = strings.ReplaceAll(agentName, "_", "-")
-
 	// Build sub-agent content with frontmatter
 	var agentContent strings.Builder
 
-	// Use extracted description or generate one
-	if description == "" {
-		description = fmt.Sprintf("Specialized agent for %s tasks", templates.NormalizeWorkflowName(agentName))
-	}
-
 	agentContent.WriteString("---\n")
-	agentContent.WriteString(fmt.Sprintf("name: %s\n", agentName))
-	agentContent.WriteString(fmt.Sprintf("description: %s\n", escapeYAMLString(description)))
+	agentContent.WriteString(fmt.Sprintf("name: %s\n", agent.Name))
+	agentContent.WriteString(fmt.Sprintf("description: \"%s\"\n", escapeYAMLString(description)))
 	agentContent.WriteString("---\n\n")
-	agentContent.WriteString(bodyContent)
-
-	// Write the agent file
-	outputPath := filepath.Join(agentsDir, fmt.Sprintf("%s.md", agentName))
-	if err := os.WriteFile(outputPath, []byte(agentContent.String()), 0644); err != nil {
-		return err
-	}
-
-	fmt.Printf("  Created: %s\n", outputPath)
-	return nil
+	agentContent.WriteString(agent.Body)
+
+	// Plan the agent file
+	plan.Add(output.File{
+		Path:      path.Join(agentsDir, fmt.Sprintf("%s.md", agent.Name)),
+		Content:   []byte(agentContent.String()),
+		Sources:   []string{agent.Path},
+		Transform: "agent converted to a sub-agent with frontmatter",
+		Provider:  p.Name(),
+	})
 }
 
-// generateWorkflowCommands creates slash commands for workflows
-func (p *ClaudeCodeProvider) generateWorkflowCommands(fs content.FileSystem, commandsDir string) error {
-	// Check if workflows directory exists
-	if _, err := fs.Stat("system/workflows"); err != nil {
-		return nil
-	}
-
-	// Find all workflow folders
-	entries, err := fs.ReadDir("system/workflows")
+// generateWorkflowCommands creates step commands and an orchestrator command for each workflow
+func (p *ClaudeCodeProvider) generateWorkflowCommands(fs content.FileSystem, plan *output.Plan, commandsDir string, config *wizard.Config) error {
+	workflows, err := loadWorkflows(fs, config)
 	if err != nil {
 		return err
 	}
 
-	for _, entry := range entries {
-		if !entry.IsDir() {
-			continue
-		}
-
-		workflowName := entry.Name()
-
-		if err := p.generateSingleWorkflowCommand(fs, commandsDir, workflowName); err != nil {
-			return fmt.Errorf("failed to generate workflow command '%s': %w", workflowName, err)
+	for _, workflow := range workflows {
+		for _, step := range workflow.Steps {
+			p.createWorkflowStepCommand(plan, step, commandsDir, workflow.Name)
 		}
+		p.createWorkflowOrchestratorCommand(plan, commandsDir, workflow)
 	}
-
 	return nil
 }
 
-// generateSingleWorkflowCommand creates step commands and an orchestrator command for one workflow
-func (p *ClaudeCodeProvider) generateSingleWorkflowCommand(fs content.FileSystem, commandsDir, workflowName string) error {
-	// Find all markdown files in the workflow directory
-	pattern := fmt.Sprintf("system/workflows/%s/*.md", workflowName)
-	files, err := fs.Glob(pattern)
-	if err != nil {
-		return err
-	}
-
-	if len(files) == 0 {
-		return nil
-	}
-
-	// Parse and sort workflow steps
-	steps := make([]templates.WorkflowStep, 0, len(files))
-	stepPattern := regexp.MustCompile(`^(\d+)[_-](.+)\.md$`)
-
-	for _, file := range files {
-		baseName := filepath.Base(file)
-		matches := stepPattern.FindStringSubmatch(baseName)
-
-		var order int
-		var stepName string
-
-		if matches != nil {
-			order, _ = strconv.Atoi(matches[1])
-			stepName = matches[2]
-		} else {
-			order = 99
-			stepName = strings.TrimSuffix(baseName, ".md")
-		}
-
-		// Normalize step name
-		stepName = strings.ReplaceAll(stepName, "_", "-")
-
-		// Generate the command name for this step
-		var commandName string
-		if matches != nil {
-			commandName = fmt.Sprintf("%s-%02d-%s", workflowName, order, stepName)
-		} else {
-			commandName = fmt.Sprintf("%s-%s", workflowName, stepName)
-		}
-
-		// Read file to extract description
-		fileContent, err := fs.ReadFile(file)
-		if err != nil {
-			return err
-		}
-
-		description := templates.ExtractStepDescription(string(fileContent))
-
-		steps = append(steps, templates.WorkflowStep{
-			Order:       order,
-			Name:        templates.NormalizeWorkflowName(stepName),
-			RuleName:    commandName, // reusing RuleName for command name
-			Description: description,
-		})
-
-		// Create the step command
-		if err := p.createWorkflowStepCommand(fs, file, commandsDir, commandName, workflowName); err != nil {
-			return err
-		}
-	}
-
-	// Sort steps by order
-	sort.Slice(steps, func(i, j int) bool {
-		return steps[i].Order < steps[j].Order
-	})
-
-	// Create the workflow orchestrator command
-	return p.createWorkflowOrchestratorCommand(commandsDir, workflowName, steps)
-}
-
 // createWorkflowStepCommand creates a slash command for a single workflow step
-func (p *ClaudeCodeProvider) createWorkflowStepCommand(fs content.FileSystem, sourcePath, commandsDir, commandName, workflowName string) error {
-	fileContent, err := fs.ReadFile(sourcePath)
-	if err != nil {
-		return err
-	}
-
+func (p *ClaudeCodeProvider) createWorkflowStepCommand(plan *output.Plan, step Step, commandsDir, workflowName string) {
 	// Build command content (slash commands don't need frontmatter in Claude Code)
 	var commandContent strings.Builder
 
 	commandContent.WriteString(fmt.Sprintf("# %s Workflow Step\n\n", templates.NormalizeWorkflowName(workflowName)))
-	commandContent.Write(fileContent)
-
-	// Write the command file
-	outputPath := filepath.Join(commandsDir, fmt.Sprintf("%s.md", commandName))
-	if err := os.WriteFile(outputPath, []byte(commandContent.String()), 0644); err != nil {
-		return err
-	}
-
-	fmt.Printf("  Created: %s\n", outputPath)
-	return nil
+	commandContent.Write(step.Content)
+
+	// Plan the command file
+	plan.Add(output.File{
+		Path:      path.Join(commandsDir, fmt.Sprintf("%s.md", step.Command)),
+		Content:   []byte(commandContent.String()),
+		Sources:   []string{step.Path},
+		Transform: "workflow step as a slash command with frontmatter",
+		Provider:  p.Name(),
+	})
 }
 
 // createWorkflowOrchestratorCommand creates the main workflow command that guides through all steps
-func (p *ClaudeCodeProvider) createWorkflowOrchestratorCommand(commandsDir, workflowName string, steps []templates.WorkflowStep) error {
-	// Build the orchestrator data
-	data := templates.WorkflowOrchestratorData{
-		WorkflowName: workflowName,
-		DisplayName:  templates.NormalizeWorkflowName(workflowName),
-		Description:  fmt.Sprintf("Complete %s workflow with %d steps. Follow each step in order.", templates.NormalizeWorkflowName(workflowName), len(steps)),
-		Steps:        steps,
-	}
-
-	// Generate orchestrator content using the template
+func (p *ClaudeCodeProvider) createWorkflowOrchestratorCommand(plan *output.Plan, commandsDir string, workflow Workflow) {
 	// For Claude Code slash commands, use / to reference other commands
-	orchestratorContent := templates.GenerateWorkflowOrchestrator(data, "/")
-
-	// Write the command file
-	outputPath := filepath.Join(commandsDir, fmt.Sprintf("%s.md", workflowName))
-	if err := os.WriteFile(outputPath, []byte(orchestratorContent), 0644); err != nil {
-		return err
-	}
-
-	fmt.Printf("  Created: %s\n", outputPath)
-	return nil
+	orchestratorContent := workflow.Orchestrator("/", func(step Step) string { return step.Command })
+
+	// Plan the command file
+	plan.Add(output.File{
+		Path:      path.Join(commandsDir, fmt.Sprintf("%s.md", workflow.Name)),
+		Content:   []byte(orchestratorContent),
+		Sources:   workflow.Sources(),
+		Transform: "workflow orchestrator command listing the steps",
+		Provider:  p.Name(),
+	})
 }
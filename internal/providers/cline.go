@@ -0,0 +1,461 @@
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/agentspack/agentspack/internal/content"
+	"github.com/agentspack/agentspack/internal/output"
+	"github.com/agentspack/agentspack/internal/stacks"
+	"github.com/agentspack/agentspack/internal/templates"
+	"github.com/agentspack/agentspack/internal/wizard"
+)
+
+// rooToolGroups maps agent tools to the Roo Code tool groups that allow them
+var rooToolGroups = map[string]string{
+	"Read":         "read",
+	"Grep":         "read",
+	"Glob":         "read",
+	"LS":           "read",
+	"Write":        "edit",
+	"Edit":         "edit",
+	"MultiEdit":    "edit",
+	"NotebookEdit": "edit",
+	"Bash":         "command",
+	"WebFetch":     "browser",
+	"WebSearch":    "browser",
+}
+
+// rooAllGroups are the tool groups of a mode whose agent doesn't list its tools
+var rooAllGroups = []string{"read", "edit", "browser", "command", "mcp"}
+
+func init() {
+	Register(&ClineProvider{})
+}
+
+// ClineProvider generates Cline rules and Roo Code rules and custom modes
+type ClineProvider struct{}
+
+// rooMode is a Roo Code custom mode in .roomodes
+type rooMode struct {
+	Slug           string   `yaml:"slug"`
+	Name           string   `yaml:"name"`
+	RoleDefinition string   `yaml:"roleDefinition"`
+	WhenToUse      string   `yaml:"whenToUse,omitempty"`
+	Groups         []string `yaml:"groups"`
+	Source         string   `yaml:"source"`
+}
+
+func (p *ClineProvider) Name() string {
+	return "cline"
+}
+
+func (p *ClineProvider) DisplayName() string {
+	return "Cline and Roo Code"
+}
+
+func (p *ClineProvider) Description() string {
+	return ".clinerules/, plus Roo Code rules, commands and custom modes in .roo/ and .roomodes"
+}
+
+func (p *ClineProvider) ContentKinds() []ContentKind {
+	return []ContentKind{KindBase, KindRules, KindAgents, KindWorkflows}
+}
+
+func (p *ClineProvider) Options() []Option {
+	return nil
+}
+
+func (p *ClineProvider) OwnedDirs() []string {
+	return []string{".clinerules", ".roo/rules", ".roo/commands"}
+}
+
+// PlannedDirs returns the rules folders of the custom modes made from agents
+func (p *ClineProvider) PlannedDirs(plan *output.Plan) []string {
+	var dirs []string
+	seen := make(map[string]bool)
+	for _, f := range plan.ByProvider(p.Name()) {
+		dir := path.Dir(f.Path)
+		if strings.HasPrefix(dir, ".roo/rules-") && !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+func (p *ClineProvider) Generate(config *wizard.Config, fs content.FileSystem, plan *output.Plan) error {
+	// The .clinerules and .roo directory structure. Roo Code reads
+	// .clinerules only when .roo/rules doesn't exist, so each tool reads
+	// its own copy.
+	clineDir := ".clinerules"
+	clineWorkflowsDir := path.Join(clineDir, "workflows")
+	rooDir := ".roo"
+	rooRulesDir := path.Join(rooDir, "rules")
+	rooCommandsDir := path.Join(rooDir, "commands")
+
+	// Cline only reads rules from the workspace, so the user scope installs
+	// the Roo Code files alone
+	var clineDirs []string
+	if !config.IsUserScope() {
+		clineDirs = []string{clineDir}
+	}
+	ruleDirs := append(clineDirs, rooRulesDir)
+
+	// 0. Generate the base rule if requested
+	if config.GenerateBase {
+		baseContent, err := fs.ReadFile("system/base/base.md")
+		if err != nil {
+			return fmt.Errorf("failed to read base.md: %w", err)
+		}
+		providerContent, err := fs.ReadFile("system/base/Cline.md")
+		if err != nil {
+			return fmt.Errorf("failed to read Cline.md: %w", err)
+		}
+		body := string(baseContent) + "\n\n" + string(providerContent)
+		sources := []string{"system/base/base.md", "system/base/Cline.md"}
+		p.writeRule(plan, ruleDirs, "base", body, sources, "base.md and Cline.md concatenated")
+	}
+
+	// 1. Generate global rules
+	rules, err := loadRules(fs, "global", p.Name(), config)
+	if err != nil {
+		return fmt.Errorf("failed to generate global rules: %w", err)
+	}
+	if len(rules) > 0 {
+		var body strings.Builder
+		body.WriteString("# Global Coding Standards\n\n")
+		body.WriteString("These rules apply to all files in the project.\n\n")
+		body.WriteString(joinRules(rules, true))
+		p.writeRule(plan, ruleDirs, "global", body.String(), rulePaths(rules), "global rules joined into a rule file")
+	}
+
+	// 2. Generate agents as Roo Code custom modes
+	agents, err := loadAgents(fs, config)
+	if err != nil {
+		return fmt.Errorf("failed to generate custom modes: %w", err)
+	}
+	if !config.IsUserScope() {
+		if err := p.generateCustomModes(plan, rooDir, agents); err != nil {
+			return fmt.Errorf("failed to generate custom modes: %w", err)
+		}
+	} else if len(agents) > 0 {
+		plan.Warn(p.Name(), fmt.Sprintf("Roo Code keeps global custom modes in the editor's settings, so %d agents were not installed", len(agents)))
+	}
+
+	// 3. Generate tech stack rules, scoped to the custom modes of the
+	// stack's agents (system/agents/<stack>/), or for every mode when the
+	// stack has none
+	selected, err := selectedStacks(config, fs)
+	if err != nil {
+		return err
+	}
+	for _, stack := range selected {
+		stack.Globs = resolveStackGlobs(config, stack.Name, stack.Globs)
+		var modes []string
+		if !config.IsUserScope() {
+			modes = stackModes(stack, agents)
+		}
+		if err := p.generateStackRules(fs, plan, clineDirs, rooDir, stack, modes, config); err != nil {
+			return fmt.Errorf("failed to generate %s rules: %w", stack.Name, err)
+		}
+	}
+
+	// 4. Generate workflows as Cline workflows and Roo Code commands
+	if err := p.generateWorkflows(fs, plan, clineWorkflowsDir, rooCommandsDir, config); err != nil {
+		return fmt.Errorf("failed to generate workflows: %w", err)
+	}
+
+	if config.IsUserScope() {
+		plan.Warn(p.Name(), "Cline keeps global rules and workflows in ~/Documents/Cline, so only the Roo Code files were installed")
+	}
+	return nil
+}
+
+// writeRule plans the rule file <name>.md in each of dirs
+func (p *ClineProvider) writeRule(plan *output.Plan, dirs []string, name, body string, sources []string, transform string) {
+	for _, dir := range dirs {
+		plan.Add(output.File{
+			Path:      path.Join(dir, name+".md"),
+			Content:   []byte(body),
+			Sources:   sources,
+			Transform: transform,
+			Provider:  p.Name(),
+		})
+	}
+}
+
+// generateStackRules creates <stack>.md in clineDirs and, for Roo Code, in
+// the rules folder of each of modes, or in .roo/rules, which every mode
+// reads, when there are no modes
+func (p *ClineProvider) generateStackRules(fs content.FileSystem, plan *output.Plan, clineDirs []string, rooDir string, stack stacks.Stack, modes []string, config *wizard.Config) error {
+	rules, err := loadRules(fs, stack.SourcePath, p.Name(), config)
+	if err != nil {
+		return err
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+
+	// Neither tool scopes rule files to paths, so the rule names the files
+	// it is meant for
+	var body strings.Builder
+	body.WriteString(fmt.Sprintf("# %s Guidelines\n\n", stack.DisplayName))
+	if len(stack.Globs) > 0 {
+		body.WriteString(fmt.Sprintf("These guidelines apply when working on files matching `%s`.\n\n", strings.Join(stack.Globs, "`, `")))
+	}
+	body.WriteString(joinRules(rules, true))
+
+	dirs := append([]string{}, clineDirs...)
+	for _, mode := range modes {
+		dirs = append(dirs, path.Join(rooDir, "rules-"+mode))
+	}
+	if len(modes) == 0 {
+		dirs = append(dirs, path.Join(rooDir, "rules"))
+	}
+	p.writeRule(plan, dirs, stack.Name, body.String(), rulePaths(rules), "stack rules joined into a rule file")
+	return nil
+}
+
+// stackModes returns the slugs of the custom modes made from the stack's agents
+func stackModes(stack stacks.Stack, agents []Agent) []string {
+	var modes []string
+	for _, agent := range agents {
+		if path.Base(path.Dir(agent.Path)) == stack.Name {
+			modes = append(modes, modeSlug(agent))
+		}
+	}
+	return modes
+}
+
+// modeSlug returns the slug of an agent's custom mode: its slug field, or its name
+func modeSlug(agent Agent) string {
+	if slug := agent.Fields["slug"]; slug != "" {
+		return slug
+	}
+	return agent.Name
+}
+
+// generateCustomModes converts agents into Roo Code custom modes in
+// .roomodes, keeping the modes written there by hand. The role definition
+// is the agent's first paragraph (or its roleDefinition field); the rest of
+// the agent becomes a rule in the mode's .roo/rules-<slug>/ folder.
+func (p *ClineProvider) generateCustomModes(plan *output.Plan, rooDir string, agents []Agent) error {
+	if len(agents) == 0 {
+		return nil
+	}
+
+	var modes []rooMode
+	for _, agent := range agents {
+		mode := rooMode{
+			Slug:      modeSlug(agent),
+			Name:      templates.NormalizeWorkflowName(agent.Name),
+			WhenToUse: agent.Description,
+			Groups:    agentGroups(agent),
+			Source:    "project",
+		}
+		if whenToUse := agent.Fields["whenToUse"]; whenToUse != "" {
+			mode.WhenToUse = whenToUse
+		}
+
+		instructions := agent.Body
+		if roleDefinition := agent.Fields["roleDefinition"]; roleDefinition != "" {
+			mode.RoleDefinition = roleDefinition
+		} else {
+			mode.RoleDefinition, instructions = splitFirstParagraph(agent.Body)
+		}
+		modes = append(modes, mode)
+
+		instructions = strings.TrimSpace(instructions)
+		if instructions == "" {
+			continue
+		}
+		plan.Add(output.File{
+			Path:      path.Join(rooDir, "rules-"+mode.Slug, mode.Slug+".md"),
+			Content:   []byte(instructions + "\n"),
+			Sources:   []string{agent.Path},
+			Transform: "agent instructions after the role definition as a mode rule",
+			Provider:  p.Name(),
+		})
+	}
+
+	content, err := mergeRooModes(nil, modes)
+	if err != nil {
+		return err
+	}
+	settings := make([]output.Setting, 0, len(modes))
+	for _, mode := range modes {
+		settings = append(settings, output.Setting{Key: "customModes", Item: mode.Slug, Field: "slug"})
+	}
+	plan.Add(output.File{
+		Path:      ".roomodes",
+		Content:   content,
+		Sources:   agentPaths(agents),
+		Transform: "agents converted to custom modes",
+		Provider:  p.Name(),
+		Patch: func(current []byte) ([]byte, error) {
+			return mergeRooModes(current, modes)
+		},
+		Settings: settings,
+	})
+	return nil
+}
+
+// mergeRooModes sets modes in the customModes list of a .roomodes file,
+// replacing the modes with the same slug and keeping the others
+func mergeRooModes(current []byte, modes []rooMode) ([]byte, error) {
+	doc, err := output.YAMLDocument(current)
+	if err != nil {
+		return nil, err
+	}
+	root := doc.Content[0]
+
+	list := output.YAMLValue(root, "customModes")
+	switch {
+	case list == nil || list.Tag == "!!null":
+		list = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		output.SetYAMLValue(root, "customModes", list)
+	case list.Kind != yaml.SequenceNode:
+		return nil, errors.New("customModes is not a list")
+	}
+
+	for _, mode := range modes {
+		node := &yaml.Node{}
+		if err := node.Encode(mode); err != nil {
+			return nil, err
+		}
+
+		replaced := false
+		for i, existing := range list.Content {
+			if slug := output.YAMLValue(existing, "slug"); slug != nil && slug.Value == mode.Slug {
+				list.Content[i] = node
+				replaced = true
+			}
+		}
+		if !replaced {
+			list.Content = append(list.Content, node)
+		}
+	}
+	return output.EncodeYAML(doc)
+}
+
+// agentGroups returns the tool groups of an agent's mode: its groups field,
+// or the groups its tools belong to, or every group when it lists neither
+func agentGroups(agent Agent) []string {
+	if groups := agent.Fields["groups"]; groups != "" {
+		return splitList(groups)
+	}
+	tools := agent.Fields["tools"]
+	if tools == "" {
+		return rooAllGroups
+	}
+
+	seen := make(map[string]bool)
+	for _, tool := range splitList(tools) {
+		group, ok := rooToolGroups[tool]
+		if strings.HasPrefix(tool, "mcp__") {
+			group, ok = "mcp", true
+		}
+		if ok {
+			seen[group] = true
+		}
+	}
+	var groups []string
+	for _, group := range rooAllGroups {
+		if seen[group] {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// splitList splits a comma-separated list, with or without brackets
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(strings.Trim(value, "[]"), ",") {
+		if item = strings.Trim(strings.TrimSpace(item), `"'`); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// splitFirstParagraph returns the first paragraph of text and the rest
+func splitFirstParagraph(text string) (first, rest string) {
+	text = strings.TrimSpace(text)
+	if i := strings.Index(text, "\n\n"); i != -1 {
+		return text[:i], strings.TrimLeft(text[i:], "\n")
+	}
+	return text, ""
+}
+
+// agentPaths returns the source paths of the agents
+func agentPaths(agents []Agent) []string {
+	paths := make([]string, len(agents))
+	for i, agent := range agents {
+		paths[i] = agent.Path
+	}
+	return paths
+}
+
+// generateWorkflows creates a Cline workflow and a Roo Code command for
+// every workflow step, plus orchestrators that call them
+func (p *ClineProvider) generateWorkflows(fs content.FileSystem, plan *output.Plan, clineWorkflowsDir, rooCommandsDir string, config *wizard.Config) error {
+	workflows, err := loadWorkflows(fs, config)
+	if err != nil {
+		return err
+	}
+
+	for _, workflow := range workflows {
+		for _, step := range workflow.Steps {
+			if !config.IsUserScope() {
+				plan.Add(output.File{
+					Path:      path.Join(clineWorkflowsDir, step.Command+".md"),
+					Content:   step.Content,
+					Sources:   []string{step.Path},
+					Transform: "workflow step copied as a Cline workflow",
+					Provider:  p.Name(),
+				})
+			}
+			p.writeCommand(plan, rooCommandsDir, step.Command, step.Description, string(step.Content), []string{step.Path}, "workflow step as a command with frontmatter")
+		}
+
+		// Cline runs workflows by file name, Roo Code commands by name
+		if !config.IsUserScope() {
+			plan.Add(output.File{
+				Path:      path.Join(clineWorkflowsDir, workflow.Name+".md"),
+				Content:   []byte(workflow.Orchestrator("/", func(step Step) string { return step.Command + ".md" })),
+				Sources:   workflow.Sources(),
+				Transform: "workflow orchestrator calling the step workflows",
+				Provider:  p.Name(),
+			})
+		}
+		description := fmt.Sprintf("Run the %s workflow step by step", workflow.Name)
+		orchestrator := workflow.Orchestrator("/", func(step Step) string { return step.Command })
+		p.writeCommand(plan, rooCommandsDir, workflow.Name, description, orchestrator, workflow.Sources(), "workflow orchestrator command listing the steps")
+	}
+	return nil
+}
+
+// writeCommand plans a Roo Code command at <commandsDir>/<name>.md
+func (p *ClineProvider) writeCommand(plan *output.Plan, commandsDir, name, description, body string, sources []string, transform string) {
+	var commandContent strings.Builder
+	if description != "" {
+		commandContent.WriteString("---\n")
+		commandContent.WriteString(fmt.Sprintf("description: \"%s\"\n", escapeYAMLString(description)))
+		commandContent.WriteString("---\n\n")
+	}
+	commandContent.WriteString(body)
+
+	plan.Add(output.File{
+		Path:      path.Join(commandsDir, name+".md"),
+		Content:   []byte(commandContent.String()),
+		Sources:   sources,
+		Transform: transform,
+		Provider:  p.Name(),
+	})
+}
@@ -0,0 +1,159 @@
+package providers
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/agentspack/agentspack/internal/output"
+	"github.com/agentspack/agentspack/internal/wizard"
+)
+
+func TestClineGenerate(t *testing.T) {
+	config := &wizard.Config{Providers: []string{"cline"}, TechStacks: []string{"backend"}, GenerateBase: true}
+	plan := output.NewPlan()
+	if err := (&ClineProvider{}).Generate(config, writeTestTemplates(t), plan); err != nil {
+		t.Fatalf("Failed to generate: %v", err)
+	}
+
+	expected := map[string]string{
+		".clinerules/base.md":                            "# Cline\n",
+		".clinerules/global.md":                          "## Coding style\n",
+		".clinerules/backend.md":                         "matching `**/*.go`, `api/**`",
+		".clinerules/workflows/planning-01-write-prd.md": "# PRD\n",
+		".clinerules/workflows/planning.md":              "**Invoke**: /planning-02-write-todos.md\n",
+		".roo/rules/base.md":                             "# Cline\n",
+		".roo/rules/global.md":                           "## Coding style\n",
+		".roo/rules-api-developer/backend.md":            "## Queries\n",
+		".roo/rules-api-developer/api-developer.md":      "Write tests first.\n",
+		".roo/commands/planning-01-write-prd.md":         "description: \"Write the PRD.\"\n",
+		".roo/commands/planning.md":                      "**Invoke**: /planning-02-write-todos\n",
+		".roomodes":                                      "  - slug: api-developer\n    name: Api Developer\n    roleDefinition: You build APIs.\n    whenToUse: Builds APIs\n    groups:\n      - read\n      - command\n",
+	}
+	for path, want := range expected {
+		f, ok := plan.Get(path)
+		if !ok {
+			t.Errorf("Expected %s to be planned", path)
+			continue
+		}
+		if !strings.Contains(string(f.Content), want) {
+			t.Errorf("Expected %s to contain %q, got:\n%s", path, want, f.Content)
+		}
+	}
+
+	// The backend rules are scoped to the mode of the backend agent only
+	if _, ok := plan.Get(".roo/rules/backend.md"); ok {
+		t.Error("Expected the backend rules to be left out of the rules every mode reads")
+	}
+
+	// The ui-designer agent has no tools or instructions past its role
+	// definition
+	if _, ok := plan.Get(".roo/rules-ui-designer/ui-designer.md"); ok {
+		t.Error("Expected no mode rule for an agent without instructions")
+	}
+	if modes, _ := plan.Get(".roomodes"); !strings.Contains(string(modes.Content), "      - mcp\n") {
+		t.Errorf("Expected an agent without tools to get every group, got:\n%s", modes.Content)
+	}
+	if dirs := (&ClineProvider{}).PlannedDirs(plan); !reflect.DeepEqual(dirs, []string{".roo/rules-api-developer"}) {
+		t.Errorf("Expected the mode rules folder to be owned, got %v", dirs)
+	}
+
+	// Cline only reads rules from the workspace, and Roo Code keeps global
+	// modes in the editor's settings
+	config.Scope = wizard.ScopeUser
+	plan = output.NewPlan()
+	if err := (&ClineProvider{}).Generate(config, writeTestTemplates(t), plan); err != nil {
+		t.Fatalf("Failed to generate for the user scope: %v", err)
+	}
+	for _, f := range plan.Files() {
+		if !strings.HasPrefix(f.Path, ".roo/rules/") && !strings.HasPrefix(f.Path, ".roo/commands/") {
+			t.Errorf("Expected only Roo Code rules and commands in the user scope, got %s", f.Path)
+		}
+	}
+	if len(plan.Warnings()) != 2 {
+		t.Errorf("Expected warnings about the agents and Cline, got %v", plan.Warnings())
+	}
+	// Without modes, the stack rules are for every mode
+	if _, ok := plan.Get(".roo/rules/backend.md"); !ok {
+		t.Error("Expected the backend rules in .roo/rules without custom modes")
+	}
+}
+
+func TestClineModeSlugOverride(t *testing.T) {
+	templates := map[string]string{
+		"system/rules/global/coding_style.md":      "## Coding style\n",
+		"system/rules/backend/stack.yaml":          "name: backend\ndisplay_name: Backend\n",
+		"system/rules/backend/database_queries.md": "## Queries\n",
+		"system/agents/backend/api_developer.md":   "---\nname: api-developer\nslug: api\n---\n\nYou build APIs.\n\nWrite tests first.\n",
+	}
+	config := &wizard.Config{Providers: []string{"cline"}, TechStacks: []string{"backend"}}
+	plan := output.NewPlan()
+	if err := (&ClineProvider{}).Generate(config, writeTemplates(t, templates), plan); err != nil {
+		t.Fatalf("Failed to generate: %v", err)
+	}
+
+	// The mode's own rule and the stack rules go to the folder of the slug
+	for _, path := range []string{".roo/rules-api/api.md", ".roo/rules-api/backend.md"} {
+		if _, ok := plan.Get(path); !ok {
+			t.Errorf("Expected %s to be planned", path)
+		}
+	}
+	for _, f := range plan.Files() {
+		if strings.HasPrefix(f.Path, ".roo/rules-api-developer/") {
+			t.Errorf("Expected no rules folder named after the agent, got %s", f.Path)
+		}
+	}
+	if modes, _ := plan.Get(".roomodes"); !strings.Contains(string(modes.Content), "  - slug: api\n") {
+		t.Errorf("Expected the mode to use the slug field, got:\n%s", modes.Content)
+	}
+}
+
+func TestClineKeepsHandWrittenModes(t *testing.T) {
+	config := &wizard.Config{Providers: []string{"cline"}}
+	plan := output.NewPlan()
+	if err := (&ClineProvider{}).Generate(config, writeTestTemplates(t), plan); err != nil {
+		t.Fatalf("Failed to generate: %v", err)
+	}
+	f, ok := plan.Get(".roomodes")
+	if !ok {
+		t.Fatal("Expected .roomodes to be planned")
+	}
+
+	current := []byte(`# Team modes
+customModes:
+  - slug: my-mode
+    name: My Mode
+    roleDefinition: You review docs.
+    groups: [read]
+  - slug: api-developer
+    name: Old API Developer
+    roleDefinition: Outdated.
+    groups: [read]
+`)
+	merged, err := output.Render(f, current)
+	if err != nil {
+		t.Fatalf("Failed to render .roomodes: %v", err)
+	}
+
+	var roomodes struct {
+		CustomModes []rooMode `yaml:"customModes"`
+	}
+	if err := yaml.Unmarshal(merged, &roomodes); err != nil {
+		t.Fatalf("Failed to parse .roomodes: %v", err)
+	}
+	var slugs []string
+	for _, mode := range roomodes.CustomModes {
+		slugs = append(slugs, mode.Slug)
+	}
+	if want := []string{"my-mode", "api-developer", "ui-designer"}; !reflect.DeepEqual(slugs, want) {
+		t.Errorf("Expected modes %v, got %v", want, slugs)
+	}
+	if roomodes.CustomModes[1].RoleDefinition != "You build APIs." {
+		t.Errorf("Expected the generated mode to replace the old one, got %+v", roomodes.CustomModes[1])
+	}
+	if !strings.HasPrefix(string(merged), "# Team modes\n") {
+		t.Errorf("Expected the comment to be kept, got:\n%s", merged)
+	}
+}
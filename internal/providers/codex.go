@@ -2,14 +2,12 @@ package providers
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
-	"regexp"
-	"sort"
-	"strconv"
+	"path"
 	"strings"
 
 	"github.com/agentspack/agentspack/internal/content"
+	"github.com/agentspack/agentspack/internal/output"
+	"github.com/agentspack/agentspack/internal/stacks"
 	"github.com/agentspack/agentspack/internal/templates"
 	"github.com/agentspack/agentspack/internal/wizard"
 )
@@ -25,83 +23,101 @@ func (p *CodexProvider) Name() string {
 	return "codex"
 }
 
-// codexStackConfigs maps wizard tech stack choices to their skill configurations
-var codexStackConfigs = map[string]struct {
-	SourcePath       string
-	SkillName        string
-	Description      string
-	ShortDescription string
-}{
-	"react": {
-		SourcePath:       "frontend/react",
-		SkillName:        "react-guidelines",
-		Description:      "Best practices for React development. Use when building React components, managing state, creating UI layouts, or working with JSX/TSX files.",
-		ShortDescription: "React component and UI development guidelines",
-	},
-	"backend": {
-		SourcePath:       "backend",
-		SkillName:        "backend-guidelines",
-		Description:      "Best practices for backend development. Use when building APIs, working with databases, designing data models, or implementing server-side logic.",
-		ShortDescription: "Backend API and database development guidelines",
-	},
+func (p *CodexProvider) DisplayName() string {
+	return "Codex"
 }
 
-func (p *CodexProvider) Generate(config *wizard.Config, fs content.FileSystem, outputDir string) error {
-	// Create the .codex directory structure
-	codexDir := filepath.Join(outputDir, ".codex")
-	skillsDir := filepath.Join(codexDir, "skills")
+func (p *CodexProvider) Description() string {
+	return "AGENTS.md and skills in .codex/"
+}
+
+func (p *CodexProvider) ContentKinds() []ContentKind {
+	return []ContentKind{KindBase, KindRules, KindSkills, KindAgents, KindWorkflows}
+}
+
+func (p *CodexProvider) Options() []Option {
+	return nil
+}
+
+func (p *CodexProvider) OwnedDirs() []string {
+	return []string{".codex/skills"}
+}
+
+func (p *CodexProvider) Generate(config *wizard.Config, fs content.FileSystem, plan *output.Plan) error {
+	// The .codex directory structure
+	skillsDir := path.Join(".codex", "skills")
 
-	if err := os.MkdirAll(skillsDir, 0755); err != nil {
-		return fmt.Errorf("failed to create codex directory: %w", err)
+	// Split each stack's rules into always-applied ones, which go into
+	// AGENTS.md, and the rest, which become the stack's skill
+	selected, err := selectedStacks(config, fs)
+	if err != nil {
+		return err
+	}
+	var alwaysApplied []Rule
+	skillRules := make(map[string][]Rule, len(selected))
+	for _, stack := range selected {
+		rules, err := loadRules(fs, stack.SourcePath, p.Name(), config)
+		if err != nil {
+			return fmt.Errorf("failed to load %s rules: %w", stack.Name, err)
+		}
+		for _, rule := range rules {
+			if rule.IsAlwaysApply(false) {
+				alwaysApplied = append(alwaysApplied, rule)
+			} else {
+				skillRules[stack.Name] = append(skillRules[stack.Name], rule)
+			}
+		}
 	}
 
 	// 1. Generate AGENTS.md with global rules (always applied)
-	if err := p.generateAgentsMD(fs, outputDir, config.GenerateBase); err != nil {
+	if err := p.generateAgentsMD(fs, plan, config, alwaysApplied); err != nil {
 		return fmt.Errorf("failed to generate AGENTS.md: %w", err)
 	}
 
 	// 2. Generate tech stack skills
-	for _, stack := range config.TechStacks {
-		stackConfig, ok := codexStackConfigs[stack]
-		if !ok {
-			fmt.Printf("Warning: no configuration for tech stack '%s', skipping\n", stack)
-			continue
-		}
-		if err := p.generateStackSkill(fs, skillsDir, stack, stackConfig); err != nil {
-			return fmt.Errorf("failed to generate %s skill: %w", stack, err)
-		}
+	for _, stack := range selected {
+		p.generateStackSkill(plan, skillsDir, stack, skillRules[stack.Name])
 	}
 
 	// 3. Generate agent skills
-	if err := p.generateAgentSkills(fs, skillsDir); err != nil {
+	if err := p.generateAgentSkills(fs, plan, skillsDir, config); err != nil {
 		return fmt.Errorf("failed to generate agent skills: %w", err)
 	}
 
-	// 4. Generate workflow skills
-	if err := p.generateWorkflowSkills(fs, skillsDir); err != nil {
+	// 4. Generate workflow skills. Codex only reads custom prompts from
+	// ~/.codex/prompts, so personal installs also get a prompt per workflow.
+	promptsDir := ""
+	if config.IsUserScope() {
+		promptsDir = path.Join(".codex", "prompts")
+	}
+	if err := p.generateWorkflowSkills(fs, plan, skillsDir, promptsDir, config); err != nil {
 		return fmt.Errorf("failed to generate workflow skills: %w", err)
 	}
 
 	return nil
 }
 
-// generateAgentsMD creates the AGENTS.md file with base content + global rules
-func (p *CodexProvider) generateAgentsMD(fs content.FileSystem, outputDir string, includeBase bool) error {
-	// Find all markdown files in global directory
-	files, err := fs.Glob("system/rules/global/*.md")
+// generateAgentsMD creates the AGENTS.md file with base content + global rules,
+// followed by the stack rules that are marked alwaysApply
+func (p *CodexProvider) generateAgentsMD(fs content.FileSystem, plan *output.Plan, config *wizard.Config, stackRules []Rule) error {
+	rules, err := loadRules(fs, "global", p.Name(), config)
 	if err != nil {
 		return err
 	}
 
-	if len(files) == 0 {
-		return fmt.Errorf("no global rule files found")
+	rules = append(rules, stackRules...)
+	if len(rules) == 0 && !config.GenerateBase {
+		// Every global rule is excluded and there is no base content
+		return nil
 	}
 
 	// Concatenate all files
 	var contentBuilder strings.Builder
+	var sources []string
+	var shared []byte
 
 	// If includeBase, prepend base.md + Codex.md content
-	if includeBase {
+	if config.GenerateBase {
 		// Read base.md
 		baseContent, err := fs.ReadFile("system/base/base.md")
 		if err != nil {
@@ -118,369 +134,214 @@ func (p *CodexProvider) generateAgentsMD(fs content.FileSystem, outputDir string
 		contentBuilder.WriteString("\n\n")
 		contentBuilder.Write(providerContent)
 		contentBuilder.WriteString("\n\n---\n\n")
-	}
-
-	contentBuilder.WriteString("# Project Guidelines\n\n")
-	contentBuilder.WriteString("These guidelines apply to all work in this project.\n\n")
-
-	for i, file := range files {
-		fileContent, err := fs.ReadFile(file)
-		if err != nil {
-			return fmt.Errorf("failed to read %s: %w", file, err)
-		}
-
-		if i > 0 {
-			contentBuilder.WriteString("\n---\n\n")
-		}
-
-		contentBuilder.Write(fileContent)
-		contentBuilder.WriteString("\n")
-	}
-
-	// Write AGENTS.md at the output root
-	outputPath := filepath.Join(outputDir, "AGENTS.md")
-	if err := os.WriteFile(outputPath, []byte(contentBuilder.String()), 0644); err != nil {
-		return fmt.Errorf("failed to write %s: %w", outputPath, err)
-	}
-
-	fmt.Printf("  Created: %s\n", outputPath)
+		sources = append(sources, "system/base/base.md", "system/base/Codex.md")
+		shared = baseContent
+	}
+
+	if len(rules) > 0 {
+		contentBuilder.WriteString("# Project Guidelines\n\n")
+		contentBuilder.WriteString("These guidelines apply to all work in this project.\n\n")
+		contentBuilder.WriteString(joinRules(rules, true))
+	}
+
+	// Plan AGENTS.md at the output root, as a managed block that keeps the
+	// project's own notes around it. Cursor reads AGENTS.md too, so base.md
+	// is shared with its section when both are selected. Personal
+	// instructions live in ~/.codex/AGENTS.md. With dedupe, it is the copy
+	// of its templates that other providers' files refer to.
+	agentsPath := "AGENTS.md"
+	if config.IsUserScope() {
+		agentsPath = path.Join(".codex", "AGENTS.md")
+	}
+	plan.Add(output.File{
+		Path:      agentsPath,
+		Content:   []byte(contentBuilder.String()),
+		Sources:   append(sources, rulePaths(rules)...),
+		Transform: "base.md, Codex.md and always-applied rules concatenated",
+		Provider:  p.Name(),
+		Managed:   true,
+		Merge:     output.MergeSections,
+		Shared:    shared,
+		Canonical: true,
+	})
 	return nil
 }
 
 // generateStackSkill creates a skill for a tech stack by concatenating its rules
-func (p *CodexProvider) generateStackSkill(fs content.FileSystem, skillsDir, stackName string, config struct {
-	SourcePath       string
-	SkillName        string
-	Description      string
-	ShortDescription string
-}) error {
-	// Create skill directory
-	skillDir := filepath.Join(skillsDir, config.SkillName)
-	if err := os.MkdirAll(skillDir, 0755); err != nil {
-		return err
-	}
-
-	// Find all markdown files in the stack directory
-	pattern := fmt.Sprintf("system/rules/%s/*.md", config.SourcePath)
-	files, err := fs.Glob(pattern)
-	if err != nil {
-		return err
-	}
-
-	// Also check subdirectories
-	subPattern := fmt.Sprintf("system/rules/%s/**/*.md", config.SourcePath)
-	subFiles, err := fs.Glob(subPattern)
-	if err == nil {
-		files = append(files, subFiles...)
-	}
-
-	if len(files) == 0 {
-		return nil
-	}
-
-	// Collect all content
-	var allContent strings.Builder
-	for i, file := range files {
-		fileContent, err := fs.ReadFile(file)
-		if err != nil {
-			return err
-		}
-
-		if i > 0 {
-			allContent.WriteString("\n---\n\n")
-		}
-
-		allContent.Write(fileContent)
-		allContent.WriteString("\n")
+func (p *CodexProvider) generateStackSkill(plan *output.Plan, skillsDir string, stack stacks.Stack, rules []Rule) {
+	if len(rules) == 0 {
+		return
 	}
 
 	// Build SKILL.md content
 	var skillContent strings.Builder
 
 	skillContent.WriteString("---\n")
-	skillContent.WriteString(fmt.Sprintf("name: %s\n", config.SkillName))
-	skillContent.WriteString(fmt.Sprintf("description: %s\n", config.Description))
+	skillContent.WriteString(fmt.Sprintf("name: %s\n", stack.SkillName()))
+	skillContent.WriteString(fmt.Sprintf("description: \"%s\"\n", escapeYAMLString(stack.SkillDescription)))
 	skillContent.WriteString("metadata:\n")
-	skillContent.WriteString(fmt.Sprintf("  short-description: %s\n", config.ShortDescription))
+	skillContent.WriteString(fmt.Sprintf("  short-description: \"%s\"\n", escapeYAMLString(stack.ShortDescription)))
 	skillContent.WriteString("---\n\n")
 
-	skillContent.WriteString(fmt.Sprintf("# %s Guidelines\n\n", templates.NormalizeWorkflowName(stackName)))
-	skillContent.WriteString(allContent.String())
-
-	// Write SKILL.md
-	outputPath := filepath.Join(skillDir, "SKILL.md")
-	if err := os.WriteFile(outputPath, []byte(skillContent.String()), 0644); err != nil {
-		return err
-	}
+	skillContent.WriteString(fmt.Sprintf("# %s Guidelines\n\n", stack.DisplayName))
+	skillContent.WriteString(joinRules(rules, true))
 
-	fmt.Printf("  Created: %s\n", outputPath)
-	return nil
+	// Plan SKILL.md in the skill's directory
+	plan.Add(output.File{
+		Path:      path.Join(skillsDir, stack.SkillName(), "SKILL.md"),
+		Content:   []byte(skillContent.String()),
+		Sources:   rulePaths(rules),
+		Transform: "stack rules joined into a skill with frontmatter",
+		Provider:  p.Name(),
+	})
 }
 
 // generateAgentSkills creates skills for each agent
-func (p *CodexProvider) generateAgentSkills(fs content.FileSystem, skillsDir string) error {
-	// Check if agents directory exists
-	if _, err := fs.Stat("system/agents"); err != nil {
-		return nil
-	}
-
-	// Find all markdown files in the agents directory
-	files, err := fs.Glob("system/agents/*.md")
+func (p *CodexProvider) generateAgentSkills(fs content.FileSystem, plan *output.Plan, skillsDir string, config *wizard.Config) error {
+	agents, err := loadAgents(fs, config)
 	if err != nil {
 		return err
 	}
 
-	// Also check subdirectories (e.g., system/agents/backend/*.md)
-	subFiles, err := fs.Glob("system/agents/**/*.md")
-	if err == nil {
-		files = append(files, subFiles...)
-	}
-
-	for _, file := range files {
-		if err := p.createAgentSkill(fs, file, skillsDir); err != nil {
-			return err
-		}
+	for _, agent := range agents {
+		p.createAgentSkill(plan, agent, skillsDir)
 	}
-
 	return nil
 }
 
-// createAgentSkill creates a Codex skill from an agent markdown file
-func (p *CodexProvider) createAgentSkill(fs content.FileSystem, sourcePath, skillsDir string) error {
-	fileContent, err := fs.ReadFile(sourcePath)
-	if err != nil {
-		return err
-	}
-
-	contentStr := string(fileContent)
-
-	// Parse frontmatter to extract agent metadata
-	agentName, description, bodyContent := parseAgentFrontmatter(contentStr)
-
-	// Generate skill name from filename if not in frontmatter
-	if agentName == "" {
-		baseName := filepath.Base(sourcePath)
-		agentName = strings.TrimSuffix(baseName, ".md")
-	}
-
-	// Normalize the name
-	skillName := strings.ReplaceAll(agentName, "_", "-")
-
-	// Create skill directory
-	skillDir := filepath.Join(skillsDir, skillName)
-	if err := os.MkdirAll(skillDir, 0755); err != nil {
-		return err
-	}
-
+// createAgentSkill creates a Codex skill from an agent template
+func (p *CodexProvider) createAgentSkill(plan *output.Plan, agent Agent, skillsDir string) {
 	// Use extracted description or generate one
+	description := agent.Description
 	if description == "" {
-		description = fmt.Sprintf("Agent: %s", agentName)
+		description = fmt.Sprintf("Agent: %s", agent.Name)
 	}
 
 	// Build SKILL.md content
 	var skillContent strings.Builder
 
 	skillContent.WriteString("---\n")
-	skillContent.WriteString(fmt.Sprintf("name: %s\n", skillName))
-	skillContent.WriteString(fmt.Sprintf("description: %s\n", escapeYAMLString(description)))
+	skillContent.WriteString(fmt.Sprintf("name: %s\n", agent.Name))
+	skillContent.WriteString(fmt.Sprintf("description: \"%s\"\n", escapeYAMLString(description)))
 	skillContent.WriteString("metadata:\n")
-	skillContent.WriteString(fmt.Sprintf("  short-description: %s agent\n", templates.NormalizeWorkflowName(agentName)))
+	skillContent.WriteString(fmt.Sprintf("  short-description: %s agent\n", templates.NormalizeWorkflowName(agent.Name)))
 	skillContent.WriteString("---\n\n")
 
-	skillContent.WriteString(bodyContent)
-
-	// Write SKILL.md
-	outputPath := filepath.Join(skillDir, "SKILL.md")
-	if err := os.WriteFile(outputPath, []byte(skillContent.String()), 0644); err != nil {
-		return err
-	}
+	skillContent.WriteString(agent.Body)
 
-	fmt.Printf("  Created: %s\n", outputPath)
-	return nil
+	// Plan SKILL.md in the skill's directory
+	plan.Add(output.File{
+		Path:      path.Join(skillsDir, agent.Name, "SKILL.md"),
+		Content:   []byte(skillContent.String()),
+		Sources:   []string{agent.Path},
+		Transform: "agent converted to a skill with frontmatter",
+		Provider:  p.Name(),
+	})
 }
 
-// generateWorkflowSkills creates skills for workflows
-func (p *CodexProvider) generateWorkflowSkills(fs content.FileSystem, skillsDir string) error {
-	// Check if workflows directory exists
-	if _, err := fs.Stat("system/workflows"); err != nil {
-		return nil
-	}
-
-	// Find all workflow folders
-	entries, err := fs.ReadDir("system/workflows")
+// generateWorkflowSkills creates step skills and an orchestrator skill for
+// each workflow, plus a prompt that starts it when promptsDir is set
+func (p *CodexProvider) generateWorkflowSkills(fs content.FileSystem, plan *output.Plan, skillsDir, promptsDir string, config *wizard.Config) error {
+	workflows, err := loadWorkflows(fs, config)
 	if err != nil {
 		return err
 	}
 
-	for _, entry := range entries {
-		if !entry.IsDir() {
-			continue
+	for _, workflow := range workflows {
+		for _, step := range workflow.Steps {
+			p.createWorkflowStepSkill(plan, step, skillsDir, workflow.Name)
 		}
+		p.createWorkflowOrchestratorSkill(plan, skillsDir, workflow)
 
-		workflowName := entry.Name()
-
-		if err := p.generateSingleWorkflowSkill(fs, skillsDir, workflowName); err != nil {
-			return fmt.Errorf("failed to generate workflow skill '%s': %w", workflowName, err)
+		if promptsDir != "" {
+			p.createWorkflowPrompt(plan, promptsDir, workflow.Name, len(workflow.Steps), workflow.Sources())
 		}
 	}
-
 	return nil
 }
 
-// generateSingleWorkflowSkill creates step skills and an orchestrator skill for one workflow
-func (p *CodexProvider) generateSingleWorkflowSkill(fs content.FileSystem, skillsDir, workflowName string) error {
-	// Find all markdown files in the workflow directory
-	pattern := fmt.Sprintf("system/workflows/%s/*.md", workflowName)
-	files, err := fs.Glob(pattern)
-	if err != nil {
-		return err
-	}
-
-	if len(files) == 0 {
-		return nil
-	}
-
-	// Parse and sort workflow steps
-	steps := make([]templates.WorkflowStep, 0, len(files))
-	stepPattern := regexp.MustCompile(`^(\d+)[_-](.+)\.md$`)
-
-	for _, file := range files {
-		baseName := filepath.Base(file)
-		matches := stepPattern.FindStringSubmatch(baseName)
-
-		var order int
-		var stepName string
-
-		if matches != nil {
-			order, _ = strconv.Atoi(matches[1])
-			stepName = matches[2]
-		} else {
-			order = 99
-			stepName = strings.TrimSuffix(baseName, ".md")
-		}
-
-		// Normalize step name
-		stepName = strings.ReplaceAll(stepName, "_", "-")
-
-		// Generate the skill name for this step
-		var skillName string
-		if matches != nil {
-			skillName = fmt.Sprintf("workflow-%s-%02d-%s", workflowName, order, stepName)
-		} else {
-			skillName = fmt.Sprintf("workflow-%s-%s", workflowName, stepName)
-		}
-
-		// Read file to extract description
-		fileContent, err := fs.ReadFile(file)
-		if err != nil {
-			return err
-		}
-
-		description := templates.ExtractStepDescription(string(fileContent))
-
-		steps = append(steps, templates.WorkflowStep{
-			Order:       order,
-			Name:        templates.NormalizeWorkflowName(stepName),
-			RuleName:    skillName, // reusing RuleName for skill name
-			Description: description,
-		})
-
-		// Create the step skill
-		if err := p.createWorkflowStepSkill(fs, file, skillsDir, skillName, workflowName); err != nil {
-			return err
-		}
-	}
-
-	// Sort steps by order
-	sort.Slice(steps, func(i, j int) bool {
-		return steps[i].Order < steps[j].Order
-	})
-
-	// Create the workflow orchestrator skill
-	return p.createWorkflowOrchestratorSkill(skillsDir, workflowName, steps)
+// stepSkillName returns the name of a workflow step's skill
+func stepSkillName(step Step) string {
+	return "workflow-" + step.Command
 }
 
 // createWorkflowStepSkill creates a Codex skill for a single workflow step
-func (p *CodexProvider) createWorkflowStepSkill(fs content.FileSystem, sourcePath, skillsDir, skillName, workflowName string) error {
-	fileContent, err := fs.ReadFile(sourcePath)
-	if err != nil {
-		return err
-	}
-
-	// Create skill directory
-	skillDir := filepath.Join(skillsDir, skillName)
-	if err := os.MkdirAll(skillDir, 0755); err != nil {
-		return err
-	}
+func (p *CodexProvider) createWorkflowStepSkill(plan *output.Plan, step Step, skillsDir, workflowName string) {
+	skillName := stepSkillName(step)
 
 	// Extract description from first heading
-	description := extractDescription(string(fileContent), "Workflow step", skillName)
+	description := extractDescription(string(step.Content), "Workflow step", skillName)
 
 	// Build SKILL.md content
 	var skillContent strings.Builder
 
 	skillContent.WriteString("---\n")
 	skillContent.WriteString(fmt.Sprintf("name: %s\n", skillName))
-	skillContent.WriteString(fmt.Sprintf("description: %s\n", escapeYAMLString(description)))
+	skillContent.WriteString(fmt.Sprintf("description: \"%s\"\n", escapeYAMLString(description)))
 	skillContent.WriteString("metadata:\n")
 	skillContent.WriteString(fmt.Sprintf("  short-description: %s workflow step\n", templates.NormalizeWorkflowName(workflowName)))
 	skillContent.WriteString("---\n\n")
-	skillContent.Write(fileContent)
-
-	// Write SKILL.md
-	outputPath := filepath.Join(skillDir, "SKILL.md")
-	if err := os.WriteFile(outputPath, []byte(skillContent.String()), 0644); err != nil {
-		return err
-	}
-
-	fmt.Printf("  Created: %s\n", outputPath)
-	return nil
+	skillContent.Write(step.Content)
+
+	// Plan SKILL.md in the skill's directory
+	plan.Add(output.File{
+		Path:      path.Join(skillsDir, skillName, "SKILL.md"),
+		Content:   []byte(skillContent.String()),
+		Sources:   []string{step.Path},
+		Transform: "workflow step as a skill with frontmatter",
+		Provider:  p.Name(),
+	})
 }
 
 // createWorkflowOrchestratorSkill creates the main workflow skill that references all steps
-func (p *CodexProvider) createWorkflowOrchestratorSkill(skillsDir, workflowName string, steps []templates.WorkflowStep) error {
-	skillName := fmt.Sprintf("workflow-%s", workflowName)
-
-	// Create skill directory
-	skillDir := filepath.Join(skillsDir, skillName)
-	if err := os.MkdirAll(skillDir, 0755); err != nil {
-		return err
-	}
-
-	// Build the orchestrator data
-	data := templates.WorkflowOrchestratorData{
-		WorkflowName: workflowName,
-		DisplayName:  templates.NormalizeWorkflowName(workflowName),
-		Description:  fmt.Sprintf("Complete %s workflow with %d steps. Follow each step in order.", templates.NormalizeWorkflowName(workflowName), len(steps)),
-		Steps:        steps,
-	}
+func (p *CodexProvider) createWorkflowOrchestratorSkill(plan *output.Plan, skillsDir string, workflow Workflow) {
+	skillName := fmt.Sprintf("workflow-%s", workflow.Name)
 
-	// Generate orchestrator content using the template
 	// For Codex, use $ to reference other skills
-	orchestratorContent := templates.GenerateWorkflowOrchestrator(data, "$")
+	orchestratorContent := workflow.Orchestrator("$", stepSkillName)
 
 	// Build SKILL.md content
 	var skillContent strings.Builder
 
 	// Generate workflow-specific description
-	workflowDescription := generateWorkflowDescription(workflowName, len(steps))
+	workflowDescription := generateWorkflowDescription(workflow.Name, len(workflow.Steps))
 
 	skillContent.WriteString("---\n")
 	skillContent.WriteString(fmt.Sprintf("name: %s\n", skillName))
 	skillContent.WriteString(fmt.Sprintf("description: %s\n", workflowDescription))
 	skillContent.WriteString("metadata:\n")
-	skillContent.WriteString(fmt.Sprintf("  short-description: Complete %s workflow (%d steps)\n", templates.NormalizeWorkflowName(workflowName), len(steps)))
+	skillContent.WriteString(fmt.Sprintf("  short-description: Complete %s workflow (%d steps)\n", templates.NormalizeWorkflowName(workflow.Name), len(workflow.Steps)))
 	skillContent.WriteString("---\n\n")
 	skillContent.WriteString(orchestratorContent)
 
-	// Write SKILL.md
-	outputPath := filepath.Join(skillDir, "SKILL.md")
-	if err := os.WriteFile(outputPath, []byte(skillContent.String()), 0644); err != nil {
-		return err
-	}
+	// Plan SKILL.md in the skill's directory
+	plan.Add(output.File{
+		Path:      path.Join(skillsDir, skillName, "SKILL.md"),
+		Content:   []byte(skillContent.String()),
+		Sources:   workflow.Sources(),
+		Transform: "workflow orchestrator skill listing the steps",
+		Provider:  p.Name(),
+	})
+}
 
-	fmt.Printf("  Created: %s\n", outputPath)
-	return nil
+// createWorkflowPrompt creates a custom prompt (/prompts:workflow-<name>) that runs the orchestrator skill
+func (p *CodexProvider) createWorkflowPrompt(plan *output.Plan, promptsDir, workflowName string, stepCount int, sources []string) {
+	skillName := fmt.Sprintf("workflow-%s", workflowName)
+
+	var promptContent strings.Builder
+	promptContent.WriteString("---\n")
+	promptContent.WriteString(fmt.Sprintf("description: \"%s\"\n", escapeYAMLString(generateWorkflowDescription(workflowName, stepCount))))
+	promptContent.WriteString("---\n\n")
+	promptContent.WriteString(fmt.Sprintf("Use the $%s skill to run the %s workflow. Follow each of its %d steps in order.\n", skillName, templates.NormalizeWorkflowName(workflowName), stepCount))
+	promptContent.WriteString("\n$ARGUMENTS\n")
+
+	plan.Add(output.File{
+		Path:      path.Join(promptsDir, skillName+".md"),
+		Content:   []byte(promptContent.String()),
+		Sources:   sources,
+		Transform: "custom prompt that runs the workflow skill",
+		Provider:  p.Name(),
+	})
 }
 
 // generateWorkflowDescription creates a helpful description for workflow orchestrators
@@ -0,0 +1,288 @@
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/agentspack/agentspack/internal/content"
+	"github.com/agentspack/agentspack/internal/output"
+	"github.com/agentspack/agentspack/internal/stacks"
+	"github.com/agentspack/agentspack/internal/wizard"
+)
+
+func init() {
+	Register(&CopilotProvider{})
+}
+
+// CopilotProvider generates GitHub Copilot files (instructions, prompt files and custom agents)
+type CopilotProvider struct{}
+
+func (p *CopilotProvider) Name() string {
+	return "copilot"
+}
+
+func (p *CopilotProvider) DisplayName() string {
+	return "GitHub Copilot"
+}
+
+func (p *CopilotProvider) Description() string {
+	return "copilot-instructions.md, path-scoped instructions, prompt files and custom agents in .github/"
+}
+
+func (p *CopilotProvider) ContentKinds() []ContentKind {
+	return []ContentKind{KindBase, KindRules, KindAgents, KindWorkflows}
+}
+
+func (p *CopilotProvider) Options() []Option {
+	return nil
+}
+
+func (p *CopilotProvider) OwnedDirs() []string {
+	return []string{".github/instructions", ".github/prompts", ".github/agents"}
+}
+
+func (p *CopilotProvider) Generate(config *wizard.Config, fs content.FileSystem, plan *output.Plan) error {
+	// Copilot keeps personal instructions and prompts in the editor's
+	// profile, which differs per editor and platform
+	if config.IsUserScope() {
+		return errors.New("GitHub Copilot reads personal instructions from the editor's settings, not the home directory; generate it with the project scope")
+	}
+
+	// The .github directory structure
+	githubDir := ".github"
+	instructionsDir := path.Join(githubDir, "instructions")
+	promptsDir := path.Join(githubDir, "prompts")
+	agentsDir := path.Join(githubDir, "agents")
+
+	// 0. Generate copilot-instructions.md with base content and the global
+	// rules that apply everywhere
+	if err := p.generateRepositoryInstructions(fs, plan, githubDir, instructionsDir, config); err != nil {
+		return fmt.Errorf("failed to generate copilot-instructions.md: %w", err)
+	}
+
+	// 1. Generate path-scoped instructions for tech stacks
+	selected, err := selectedStacks(config, fs)
+	if err != nil {
+		return err
+	}
+	for _, stack := range selected {
+		stack.Globs = resolveStackGlobs(config, stack.Name, stack.Globs)
+		if err := p.generateStackInstructions(fs, plan, instructionsDir, stack, config); err != nil {
+			return fmt.Errorf("failed to generate %s instructions: %w", stack.Name, err)
+		}
+	}
+
+	// 2. Generate agents as custom agents
+	if err := p.generateCustomAgents(fs, plan, agentsDir, config); err != nil {
+		return fmt.Errorf("failed to generate custom agents: %w", err)
+	}
+
+	// 3. Generate workflows as prompt files
+	if err := p.generateWorkflowPrompts(fs, plan, promptsDir, config); err != nil {
+		return fmt.Errorf("failed to generate workflow prompts: %w", err)
+	}
+
+	return nil
+}
+
+// generateRepositoryInstructions creates .github/copilot-instructions.md
+// from base.md + Copilot.md and the global rules. Global rules with their
+// own scope get an instructions file of their own.
+func (p *CopilotProvider) generateRepositoryInstructions(fs content.FileSystem, plan *output.Plan, githubDir, instructionsDir string, config *wizard.Config) error {
+	rules, err := loadRules(fs, "global", p.Name(), config)
+	if err != nil {
+		return err
+	}
+
+	var merged []Rule
+	for _, rule := range rules {
+		if !rule.Scoped() {
+			merged = append(merged, rule)
+			continue
+		}
+		ruleName := "global-" + rule.Name
+		globs := rule.Globs
+		if rule.IsAlwaysApply(len(globs) == 0) {
+			globs = nil
+		}
+		p.writeInstructions(plan, instructionsDir, ruleName, rule.DescriptionOr("Global", ruleName), globs, rule.Body, []string{rule.Path})
+	}
+
+	var contentBuilder strings.Builder
+	var sources []string
+	if config.GenerateBase {
+		baseContent, err := fs.ReadFile("system/base/base.md")
+		if err != nil {
+			return fmt.Errorf("failed to read base.md: %w", err)
+		}
+		providerContent, err := fs.ReadFile("system/base/Copilot.md")
+		if err != nil {
+			return fmt.Errorf("failed to read Copilot.md: %w", err)
+		}
+
+		contentBuilder.Write(baseContent)
+		contentBuilder.WriteString("\n\n")
+		contentBuilder.Write(providerContent)
+		sources = append(sources, "system/base/base.md", "system/base/Copilot.md")
+	}
+
+	if len(merged) > 0 {
+		if contentBuilder.Len() > 0 {
+			contentBuilder.WriteString("\n\n---\n\n")
+		}
+		contentBuilder.WriteString("# Project Guidelines\n\n")
+		contentBuilder.WriteString("These guidelines apply to all work in this project.\n\n")
+		contentBuilder.WriteString(joinRules(merged, false))
+	}
+	if contentBuilder.Len() == 0 {
+		return nil
+	}
+
+	// Plan copilot-instructions.md as a managed block that keeps the
+	// repository's own instructions around it
+	plan.Add(output.File{
+		Path:      path.Join(githubDir, "copilot-instructions.md"),
+		Content:   []byte(contentBuilder.String()),
+		Sources:   append(sources, rulePaths(merged)...),
+		Transform: "base.md, Copilot.md and global rules concatenated",
+		Provider:  p.Name(),
+		Managed:   true,
+	})
+	return nil
+}
+
+// generateStackInstructions creates <stack>.instructions.md, applied to the
+// stack's globs. Rules with their own globs or alwaysApply get a file of
+// their own.
+func (p *CopilotProvider) generateStackInstructions(fs content.FileSystem, plan *output.Plan, instructionsDir string, stack stacks.Stack, config *wizard.Config) error {
+	rules, err := loadRules(fs, stack.SourcePath, p.Name(), config)
+	if err != nil {
+		return err
+	}
+
+	var merged []Rule
+	for _, rule := range rules {
+		if !rule.Scoped() {
+			merged = append(merged, rule)
+			continue
+		}
+
+		ruleName := fmt.Sprintf("%s-%s", stack.Name, rule.Name)
+		globs := stack.Globs
+		if len(rule.Globs) > 0 {
+			globs = rule.Globs
+		}
+		if rule.IsAlwaysApply(false) {
+			globs = nil
+		}
+		p.writeInstructions(plan, instructionsDir, ruleName, rule.DescriptionOr(stack.RuleDescription, ruleName), globs, rule.Body, []string{rule.Path})
+	}
+
+	if len(merged) == 0 {
+		return nil
+	}
+
+	var body strings.Builder
+	body.WriteString(fmt.Sprintf("# %s Guidelines\n\n", stack.DisplayName))
+	body.WriteString(joinRules(merged, false))
+
+	p.writeInstructions(plan, instructionsDir, stack.Name, fmt.Sprintf("%s development guidelines", stack.DisplayName), stack.Globs, body.String(), rulePaths(merged))
+	return nil
+}
+
+// writeInstructions plans <instructionsDir>/<name>.instructions.md, applied
+// to files matching globs, or to every file without globs
+func (p *CopilotProvider) writeInstructions(plan *output.Plan, instructionsDir, name, description string, globs []string, body string, sources []string) {
+	applyTo := "**"
+	if len(globs) > 0 {
+		applyTo = strings.Join(globs, ",")
+	}
+
+	var instructionsContent strings.Builder
+	instructionsContent.WriteString("---\n")
+	instructionsContent.WriteString(fmt.Sprintf("description: \"%s\"\n", escapeYAMLString(description)))
+	instructionsContent.WriteString(fmt.Sprintf("applyTo: \"%s\"\n", escapeYAMLString(applyTo)))
+	instructionsContent.WriteString("---\n\n")
+	instructionsContent.WriteString(body)
+
+	plan.Add(output.File{
+		Path:      path.Join(instructionsDir, name+".instructions.md"),
+		Content:   []byte(instructionsContent.String()),
+		Sources:   sources,
+		Transform: "rules joined into instructions with an applyTo glob",
+		Provider:  p.Name(),
+	})
+}
+
+// generateCustomAgents creates a custom agent for each agent template
+func (p *CopilotProvider) generateCustomAgents(fs content.FileSystem, plan *output.Plan, agentsDir string, config *wizard.Config) error {
+	agents, err := loadAgents(fs, config)
+	if err != nil {
+		return err
+	}
+
+	for _, agent := range agents {
+		description := agent.Description
+		if description == "" {
+			description = fmt.Sprintf("Agent: %s", agent.Name)
+		}
+
+		var agentContent strings.Builder
+		agentContent.WriteString("---\n")
+		agentContent.WriteString(fmt.Sprintf("name: %s\n", agent.Name))
+		agentContent.WriteString(fmt.Sprintf("description: \"%s\"\n", escapeYAMLString(description)))
+		agentContent.WriteString("---\n\n")
+		agentContent.WriteString(agent.Body)
+
+		plan.Add(output.File{
+			Path:      path.Join(agentsDir, agent.Name+".agent.md"),
+			Content:   []byte(agentContent.String()),
+			Sources:   []string{agent.Path},
+			Transform: "agent converted to a custom agent with frontmatter",
+			Provider:  p.Name(),
+		})
+	}
+	return nil
+}
+
+// generateWorkflowPrompts creates a prompt file for every workflow step and
+// an orchestrator prompt that runs them with /<prompt>
+func (p *CopilotProvider) generateWorkflowPrompts(fs content.FileSystem, plan *output.Plan, promptsDir string, config *wizard.Config) error {
+	workflows, err := loadWorkflows(fs, config)
+	if err != nil {
+		return err
+	}
+
+	for _, workflow := range workflows {
+		for _, step := range workflow.Steps {
+			p.writePrompt(plan, promptsDir, step.Command, step.Description, string(step.Content), []string{step.Path}, "workflow step as an agent mode prompt file")
+		}
+
+		description := fmt.Sprintf("Run the %s workflow step by step", workflow.Name)
+		orchestrator := workflow.Orchestrator("/", func(step Step) string { return step.Command })
+		p.writePrompt(plan, promptsDir, workflow.Name, description, orchestrator, workflow.Sources(), "workflow orchestrator prompt listing the steps")
+	}
+	return nil
+}
+
+// writePrompt plans an agent mode prompt file at <promptsDir>/<name>.prompt.md
+func (p *CopilotProvider) writePrompt(plan *output.Plan, promptsDir, name, description, body string, sources []string, transform string) {
+	var promptContent strings.Builder
+	promptContent.WriteString("---\n")
+	promptContent.WriteString("mode: agent\n")
+	if description != "" {
+		promptContent.WriteString(fmt.Sprintf("description: \"%s\"\n", escapeYAMLString(description)))
+	}
+	promptContent.WriteString("---\n\n")
+	promptContent.WriteString(body)
+
+	plan.Add(output.File{
+		Path:      path.Join(promptsDir, name+".prompt.md"),
+		Content:   []byte(promptContent.String()),
+		Sources:   sources,
+		Transform: transform,
+		Provider:  p.Name(),
+	})
+}
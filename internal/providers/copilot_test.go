@@ -0,0 +1,42 @@
+package providers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/agentspack/agentspack/internal/output"
+	"github.com/agentspack/agentspack/internal/wizard"
+)
+
+func TestCopilotGenerate(t *testing.T) {
+	config := &wizard.Config{Providers: []string{"copilot"}, TechStacks: []string{"backend"}, GenerateBase: true}
+	plan := output.NewPlan()
+	if err := (&CopilotProvider{}).Generate(config, writeTestTemplates(t), plan); err != nil {
+		t.Fatalf("Failed to generate: %v", err)
+	}
+
+	expected := map[string]string{
+		".github/copilot-instructions.md":                         "# Copilot\n",
+		".github/instructions/backend.instructions.md":            "applyTo: \"**/*.go,api/**\"\n",
+		".github/instructions/backend-migrations.instructions.md": "applyTo: \"migrations/**\"\n",
+		".github/agents/ui-designer.agent.md":                     "description: \"Designs interfaces\"\n",
+		".github/agents/api-developer.agent.md":                   "name: api-developer\n",
+		".github/prompts/planning-01-write-prd.prompt.md":         "mode: agent\n",
+		".github/prompts/planning.prompt.md":                      "**Invoke**: /planning-02-write-todos\n",
+	}
+	for path, want := range expected {
+		f, ok := plan.Get(path)
+		if !ok {
+			t.Errorf("Expected %s to be planned", path)
+			continue
+		}
+		if !strings.Contains(string(f.Content), want) {
+			t.Errorf("Expected %s to contain %q, got:\n%s", path, want, f.Content)
+		}
+	}
+
+	config.Scope = wizard.ScopeUser
+	if err := (&CopilotProvider{}).Generate(config, writeTestTemplates(t), output.NewPlan()); err == nil {
+		t.Error("Expected the user scope to be rejected")
+	}
+}
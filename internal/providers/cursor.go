@@ -2,15 +2,12 @@ package providers
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
-	"regexp"
-	"sort"
-	"strconv"
+	"path"
 	"strings"
 
 	"github.com/agentspack/agentspack/internal/content"
-	"github.com/agentspack/agentspack/internal/templates"
+	"github.com/agentspack/agentspack/internal/output"
+	"github.com/agentspack/agentspack/internal/stacks"
 	"github.com/agentspack/agentspack/internal/wizard"
 )
 
@@ -25,74 +22,74 @@ func (p *CursorProvider) Name() string {
 	return "cursor"
 }
 
-// TechStackConfig defines how to handle a specific tech stack
-type TechStackConfig struct {
-	// SourcePath is the relative path under system/rules (e.g., "frontend/react")
-	SourcePath string
-	// Globs are the file patterns this stack applies to
-	Globs []string
-	// Description prefix for the rules
-	Description string
+func (p *CursorProvider) DisplayName() string {
+	return "Cursor"
 }
 
-// techStackConfigs maps wizard tech stack choices to their configurations
-var techStackConfigs = map[string]TechStackConfig{
-	"react": {
-		SourcePath:  "frontend/react",
-		Globs:       []string{"*.tsx", "*.jsx", "src/components/**", "src/pages/**", "src/app/**"},
-		Description: "React component",
-	},
-	"backend": {
-		SourcePath:  "backend",
-		Globs:       []string{"*.go", "*.py", "*.ts", "src/api/**", "src/server/**", "api/**", "server/**"},
-		Description: "Backend API",
-	},
+func (p *CursorProvider) Description() string {
+	return "AGENTS.md, rules and commands in .cursor/"
 }
 
-func (p *CursorProvider) Generate(config *wizard.Config, fs content.FileSystem, outputDir string) error {
-	// Create the output directory structure directly in the user's chosen directory
-	rulesDir := filepath.Join(outputDir, ".cursor", "rules")
-	if err := os.MkdirAll(rulesDir, 0755); err != nil {
-		return fmt.Errorf("failed to create cursor rules directory: %w", err)
-	}
+func (p *CursorProvider) ContentKinds() []ContentKind {
+	return []ContentKind{KindBase, KindRules, KindAgents, KindWorkflows}
+}
+
+func (p *CursorProvider) Options() []Option {
+	return nil
+}
 
-	// Create commands directory for workflows
-	commandsDir := filepath.Join(outputDir, ".cursor", "commands")
-	if err := os.MkdirAll(commandsDir, 0755); err != nil {
-		return fmt.Errorf("failed to create cursor commands directory: %w", err)
+func (p *CursorProvider) OwnedDirs() []string {
+	return []string{".cursor/rules", ".cursor/commands"}
+}
+
+func (p *CursorProvider) Generate(config *wizard.Config, fs content.FileSystem, plan *output.Plan) error {
+	// Rules and workflow commands live directly in the user's chosen directory
+	rulesDir := path.Join(".cursor", "rules")
+	commandsDir := path.Join(".cursor", "commands")
+
+	// Cursor only reads commands from the home directory; user rules are
+	// kept in its settings, so they're planned as a file to paste there
+	if config.IsUserScope() {
+		if err := p.generateUserRules(fs, plan, config); err != nil {
+			return fmt.Errorf("failed to generate user rules: %w", err)
+		}
+		if err := p.generateWorkflowCommands(fs, plan, commandsDir, config); err != nil {
+			return fmt.Errorf("failed to generate workflow commands: %w", err)
+		}
+		return nil
 	}
 
 	// 0. Generate AGENTS.md base file if requested
 	if config.GenerateBase {
-		if err := p.generateBaseFile(fs, outputDir); err != nil {
+		if err := p.generateBaseFile(fs, plan); err != nil {
 			return fmt.Errorf("failed to generate AGENTS.md: %w", err)
 		}
 	}
 
-	// 1. Generate global rules (concatenated)
-	if err := p.generateGlobalRules(fs, rulesDir); err != nil {
+	// 1. Generate global rules (concatenated, except those with their own scope)
+	if err := p.generateGlobalRules(fs, plan, rulesDir, config); err != nil {
 		return fmt.Errorf("failed to generate global rules: %w", err)
 	}
 
 	// 2. Generate tech stack specific rules
-	for _, stack := range config.TechStacks {
-		stackConfig, ok := techStackConfigs[stack]
-		if !ok {
-			fmt.Printf("Warning: no configuration for tech stack '%s', skipping\n", stack)
-			continue
-		}
-		if err := p.generateStackRules(fs, rulesDir, stack, stackConfig); err != nil {
-			return fmt.Errorf("failed to generate %s rules: %w", stack, err)
+	selected, err := selectedStacks(config, fs)
+	if err != nil {
+		return err
+	}
+	for _, stack := range selected {
+		stack.Globs = resolveStackGlobs(config, stack.Name, stack.Globs)
+		if err := p.generateStackRules(fs, plan, rulesDir, stack, config); err != nil {
+			return fmt.Errorf("failed to generate %s rules: %w", stack.Name, err)
 		}
 	}
 
 	// 3. Generate agent rules
-	if err := p.generateAgentRules(fs, rulesDir); err != nil {
+	if err := p.generateAgentRules(fs, plan, rulesDir, config); err != nil {
 		return fmt.Errorf("failed to generate agent rules: %w", err)
 	}
 
 	// 4. Generate workflow commands (Cursor supports /commands like Claude Code)
-	if err := p.generateWorkflowCommands(fs, commandsDir); err != nil {
+	if err := p.generateWorkflowCommands(fs, plan, commandsDir, config); err != nil {
 		return fmt.Errorf("failed to generate workflow commands: %w", err)
 	}
 
@@ -100,7 +97,7 @@ func (p *CursorProvider) Generate(config *wizard.Config, fs content.FileSystem,
 }
 
 // generateBaseFile creates the AGENTS.md file from base.md + Cursor.md
-func (p *CursorProvider) generateBaseFile(fs content.FileSystem, outputDir string) error {
+func (p *CursorProvider) generateBaseFile(fs content.FileSystem, plan *output.Plan) error {
 	// Read base.md
 	baseContent, err := fs.ReadFile("system/base/base.md")
 	if err != nil {
@@ -119,137 +116,155 @@ func (p *CursorProvider) generateBaseFile(fs content.FileSystem, outputDir strin
 	contentBuilder.WriteString("\n\n")
 	contentBuilder.Write(providerContent)
 
-	// Write AGENTS.md to output directory root
-	outputPath := filepath.Join(outputDir, "AGENTS.md")
-	if err := os.WriteFile(outputPath, []byte(contentBuilder.String()), 0644); err != nil {
-		return fmt.Errorf("failed to write AGENTS.md: %w", err)
-	}
-
-	fmt.Printf("  Created: %s\n", outputPath)
+	// Plan AGENTS.md at the output directory root, as a managed block that
+	// keeps the project's own notes around it. Codex reads AGENTS.md too, so
+	// base.md is shared with its section when both are selected. With
+	// dedupe, Cursor's always-applied rules are left out when AGENTS.md holds
+	// their templates.
+	plan.Add(output.File{
+		Path:      "AGENTS.md",
+		Content:   []byte(contentBuilder.String()),
+		Sources:   []string{"system/base/base.md", "system/base/Cursor.md"},
+		Transform: "base.md and Cursor.md concatenated",
+		Provider:  p.Name(),
+		Managed:   true,
+		Merge:     output.MergeSections,
+		Shared:    baseContent,
+		Canonical: true,
+	})
 	return nil
 }
 
-// generateGlobalRules concatenates all global rules into a single RULE.md
-func (p *CursorProvider) generateGlobalRules(fs content.FileSystem, cursorDir string) error {
-	globalRuleDir := filepath.Join(cursorDir, "global")
-
-	if err := os.MkdirAll(globalRuleDir, 0755); err != nil {
-		return err
-	}
-
-	// Find all markdown files in global directory
-	files, err := fs.Glob("system/rules/global/*.md")
+// generateUserRules concatenates base.md, Cursor.md and the global rules
+// into .cursor/user-rules.md, for Cursor Settings > Rules > User Rules
+func (p *CursorProvider) generateUserRules(fs content.FileSystem, plan *output.Plan, config *wizard.Config) error {
+	rules, err := loadRules(fs, "global", p.Name(), config)
 	if err != nil {
 		return err
 	}
 
-	if len(files) == 0 {
-		return fmt.Errorf("no global rule files found")
-	}
-
-	// Concatenate all files
 	var contentBuilder strings.Builder
-
-	// Write frontmatter
-	contentBuilder.WriteString("---\n")
-	contentBuilder.WriteString("description: \"Global coding standards and best practices\"\n")
-	contentBuilder.WriteString("alwaysApply: true\n")
-	contentBuilder.WriteString("---\n\n")
-
-	contentBuilder.WriteString("# Global Coding Standards\n\n")
-	contentBuilder.WriteString("These rules apply to all files in the project.\n\n")
-
-	for i, file := range files {
-		fileContent, err := fs.ReadFile(file)
+	var sources []string
+	if config.GenerateBase {
+		baseContent, err := fs.ReadFile("system/base/base.md")
 		if err != nil {
-			return fmt.Errorf("failed to read %s: %w", file, err)
+			return fmt.Errorf("failed to read base.md: %w", err)
 		}
-
-		if i > 0 {
-			contentBuilder.WriteString("\n---\n\n")
+		providerContent, err := fs.ReadFile("system/base/Cursor.md")
+		if err != nil {
+			return fmt.Errorf("failed to read Cursor.md: %w", err)
 		}
 
-		// Add the file content
-		contentBuilder.Write(fileContent)
-		contentBuilder.WriteString("\n")
+		contentBuilder.Write(baseContent)
+		contentBuilder.WriteString("\n\n")
+		contentBuilder.Write(providerContent)
+		sources = append(sources, "system/base/base.md", "system/base/Cursor.md")
 	}
 
-	// Write the concatenated file
-	outputPath := filepath.Join(globalRuleDir, "RULE.md")
-	if err := os.WriteFile(outputPath, []byte(contentBuilder.String()), 0644); err != nil {
-		return fmt.Errorf("failed to write %s: %w", outputPath, err)
+	if len(rules) > 0 {
+		if contentBuilder.Len() > 0 {
+			contentBuilder.WriteString("\n\n---\n\n")
+		}
+		contentBuilder.WriteString(joinRules(rules, false))
+	}
+	if contentBuilder.Len() == 0 {
+		return nil
 	}
 
-	fmt.Printf("  Created: %s\n", outputPath)
+	plan.Add(output.File{
+		Path:      path.Join(".cursor", "user-rules.md"),
+		Content:   []byte(contentBuilder.String()),
+		Sources:   append(sources, rulePaths(rules)...),
+		Transform: "base.md, Cursor.md and global rules concatenated for User Rules",
+		Provider:  p.Name(),
+	})
 	return nil
 }
 
-// generateStackRules creates individual rule files for each stack template
-func (p *CursorProvider) generateStackRules(fs content.FileSystem, cursorDir, stackName string, config TechStackConfig) error {
-	// Find all markdown files in the stack directory
-	pattern := fmt.Sprintf("system/rules/%s/*.md", config.SourcePath)
-	files, err := fs.Glob(pattern)
+// generateGlobalRules concatenates all global rules into a single RULE.md
+func (p *CursorProvider) generateGlobalRules(fs content.FileSystem, plan *output.Plan, cursorDir string, config *wizard.Config) error {
+	rules, err := loadRules(fs, "global", p.Name(), config)
 	if err != nil {
 		return err
 	}
 
-	// Also check subdirectories
-	subPattern := fmt.Sprintf("system/rules/%s/**/*.md", config.SourcePath)
-	subFiles, err := fs.Glob(subPattern)
-	if err == nil {
-		files = append(files, subFiles...)
+	// Rules with their own globs or alwaysApply become separate rules
+	var merged []Rule
+	for _, rule := range rules {
+		if !rule.Scoped() {
+			merged = append(merged, rule)
+			continue
+		}
+		ruleName := "global-" + rule.Name
+		description := rule.DescriptionOr("Global", ruleName)
+		p.writeRule(plan, cursorDir, ruleName, description, rule.IsAlwaysApply(len(rule.Globs) == 0), rule.Globs, rule.Body, []string{rule.Path})
 	}
 
-	for _, file := range files {
-		if err := p.createRuleFromFile(fs, file, cursorDir, stackName, config); err != nil {
-			return err
-		}
+	if len(merged) == 0 {
+		return nil
 	}
 
+	// Concatenate all files
+	var contentBuilder strings.Builder
+	contentBuilder.WriteString("# Global Coding Standards\n\n")
+	contentBuilder.WriteString("These rules apply to all files in the project.\n\n")
+	contentBuilder.WriteString(joinRules(merged, false))
+
+	p.writeRule(plan, cursorDir, "global", "Global coding standards and best practices", true, nil, contentBuilder.String(), rulePaths(merged))
 	return nil
 }
 
-// createRuleFromFile creates a Cursor rule from a single source markdown file
-func (p *CursorProvider) createRuleFromFile(fs content.FileSystem, sourcePath, cursorDir, stackName string, config TechStackConfig) error {
-	// Read the source file
-	fileContent, err := fs.ReadFile(sourcePath)
+// generateStackRules creates individual rule files for each stack template
+func (p *CursorProvider) generateStackRules(fs content.FileSystem, plan *output.Plan, cursorDir string, stack stacks.Stack, config *wizard.Config) error {
+	rules, err := loadRules(fs, stack.SourcePath, p.Name(), config)
 	if err != nil {
 		return err
 	}
 
-	// Generate rule name from filename (replace underscores with hyphens)
-	baseName := filepath.Base(sourcePath)
-	ruleName := strings.TrimSuffix(baseName, ".md")
-	ruleName = strings.ReplaceAll(ruleName, "_", "-")
-	ruleName = fmt.Sprintf("%s-%s", stackName, ruleName)
-
-	// Create the rule directory
-	ruleDir := filepath.Join(cursorDir, ruleName)
-	if err := os.MkdirAll(ruleDir, 0755); err != nil {
-		return err
+	for _, rule := range rules {
+		p.createRuleFromFile(plan, cursorDir, rule, stack)
 	}
 
-	// Build the RULE.md content with frontmatter
-	var ruleContent strings.Builder
+	return nil
+}
+
+// createRuleFromFile creates a Cursor rule from a single source rule. The
+// rule's frontmatter takes precedence over the stack's description and globs.
+func (p *CursorProvider) createRuleFromFile(plan *output.Plan, cursorDir string, rule Rule, stack stacks.Stack) {
+	ruleName := fmt.Sprintf("%s-%s", stack.Name, rule.Name)
+
+	globs := stack.Globs
+	if len(rule.Globs) > 0 {
+		globs = rule.Globs
+	}
 
 	// Extract a description from the first heading or use filename
-	description := extractDescription(string(fileContent), config.Description, ruleName)
+	description := rule.DescriptionOr(stack.RuleDescription, ruleName)
 
-	ruleContent.WriteString("---\n")
-	ruleContent.WriteString(fmt.Sprintf("description: \"%s\"\n", description))
-	ruleContent.WriteString("alwaysApply: false\n")
-	ruleContent.WriteString(fmt.Sprintf("globs: %s\n", formatGlobs(config.Globs)))
-	ruleContent.WriteString("---\n\n")
-	ruleContent.Write(fileContent)
+	p.writeRule(plan, cursorDir, ruleName, description, rule.IsAlwaysApply(false), globs, rule.Body, []string{rule.Path})
+}
 
-	// Write the rule file
-	outputPath := filepath.Join(ruleDir, "RULE.md")
-	if err := os.WriteFile(outputPath, []byte(ruleContent.String()), 0644); err != nil {
-		return err
+// writeRule plans a Cursor rule at <cursorDir>/<ruleName>/RULE.md
+func (p *CursorProvider) writeRule(plan *output.Plan, cursorDir, ruleName, description string, alwaysApply bool, globs []string, body string, sources []string) {
+	// Build the RULE.md content with frontmatter
+	var ruleContent strings.Builder
+	ruleContent.WriteString("---\n")
+	ruleContent.WriteString(fmt.Sprintf("description: \"%s\"\n", escapeYAMLString(description)))
+	ruleContent.WriteString(fmt.Sprintf("alwaysApply: %t\n", alwaysApply))
+	if len(globs) > 0 {
+		ruleContent.WriteString(fmt.Sprintf("globs: %s\n", formatGlobs(globs)))
 	}
-
-	fmt.Printf("  Created: %s\n", outputPath)
-	return nil
+	ruleContent.WriteString("---\n\n")
+	ruleContent.WriteString(body)
+
+	plan.Add(output.File{
+		Path:      path.Join(cursorDir, ruleName, "RULE.md"),
+		Content:   []byte(ruleContent.String()),
+		Sources:   sources,
+		Transform: "rules joined into a rule with frontmatter",
+		Provider:  p.Name(),
+		Covered:   alwaysApply,
+	})
 }
 
 // extractDescription tries to get a meaningful description from the content
@@ -284,86 +299,47 @@ func formatGlobs(globs []string) string {
 }
 
 // generateAgentRules creates individual rule files for each agent
-func (p *CursorProvider) generateAgentRules(fs content.FileSystem, cursorDir string) error {
-	// Check if agents directory exists
-	if _, err := fs.Stat("system/agents"); err != nil {
-		// No agents directory, skip silently
-		return nil
-	}
-
-	// Find all markdown files in the agents directory
-	files, err := fs.Glob("system/agents/*.md")
+func (p *CursorProvider) generateAgentRules(fs content.FileSystem, plan *output.Plan, cursorDir string, config *wizard.Config) error {
+	agents, err := loadAgents(fs, config)
 	if err != nil {
 		return err
 	}
 
-	// Also check subdirectories (e.g., system/agents/backend/*.md)
-	subFiles, err := fs.Glob("system/agents/**/*.md")
-	if err == nil {
-		files = append(files, subFiles...)
+	for _, agent := range agents {
+		p.createAgentRule(plan, agent, cursorDir)
 	}
-
-	for _, file := range files {
-		if err := p.createAgentRule(fs, file, cursorDir); err != nil {
-			return err
-		}
-	}
-
 	return nil
 }
 
-// createAgentRule creates a Cursor rule from an agent markdown file
-func (p *CursorProvider) createAgentRule(fs content.FileSystem, sourcePath, cursorDir string) error {
-	// Read the source file
-	fileContent, err := fs.ReadFile(sourcePath)
-	if err != nil {
-		return err
-	}
-
-	contentStr := string(fileContent)
-
-	// Parse frontmatter to extract agent metadata
-	agentName, description, bodyContent := parseAgentFrontmatter(contentStr)
-
-	// Generate rule name from filename if not in frontmatter
-	if agentName == "" {
-		baseName := filepath.Base(sourcePath)
-		agentName = strings.TrimSuffix(baseName, ".md")
-	}
+// createAgentRule creates a Cursor rule from an agent template
+func (p *CursorProvider) createAgentRule(plan *output.Plan, agent Agent, cursorDir string) {
+	ruleName := "agent-" + agent.Name
 
-	// Normalize the name (replace underscores with hyphens)
-	ruleName := "agent-" + strings.ReplaceAll(agentName, "_", "-")
-
-	// Create the rule directory
-	ruleDir := filepath.Join(cursorDir, ruleName)
-	if err := os.MkdirAll(ruleDir, 0755); err != nil {
-		return err
+	// Use extracted description or generate one
+	description := agent.Description
+	if description == "" {
+		description = fmt.Sprintf("Agent: %s", agent.Name)
 	}
 
 	// Build the RULE.md content with frontmatter
 	var ruleContent strings.Builder
 
-	// Use extracted description or generate one
-	if description == "" {
-		description = fmt.Sprintf("Agent: %s", agentName)
-	}
-
 	ruleContent.WriteString("---\n")
 	ruleContent.WriteString(fmt.Sprintf("description: \"%s\"\n", escapeYAMLString(description)))
 	ruleContent.WriteString("alwaysApply: false\n")
 	ruleContent.WriteString("---\n\n")
 
 	// Write the body content (without frontmatter)
-	ruleContent.WriteString(bodyContent)
-
-	// Write the rule file
-	outputPath := filepath.Join(ruleDir, "RULE.md")
-	if err := os.WriteFile(outputPath, []byte(ruleContent.String()), 0644); err != nil {
-		return err
-	}
-
-	fmt.Printf("  Created: %s\n", outputPath)
-	return nil
+	ruleContent.WriteString(agent.Body)
+
+	// Plan the rule file
+	plan.Add(output.File{
+		Path:      path.Join(cursorDir, ruleName, "RULE.md"),
+		Content:   []byte(ruleContent.String()),
+		Sources:   []string{agent.Path},
+		Transform: "agent converted to a manually applied rule",
+		Provider:  p.Name(),
+	})
 }
 
 // parseAgentFrontmatter extracts name, description, and body from agent markdown
@@ -438,8 +414,10 @@ func parseAgentFrontmatter(content string) (name, description, body string) {
 	return name, description, body
 }
 
-// escapeYAMLString escapes special characters in a YAML string value
+// escapeYAMLString escapes special characters in a double-quoted YAML string value
 func escapeYAMLString(s string) string {
+	// Escape backslashes before the escapes added below
+	s = strings.ReplaceAll(s, "\\", "\\\\")
 	// Replace double quotes with escaped quotes
 	s = strings.ReplaceAll(s, "\"", "\\\"")
 	// Replace newlines
@@ -449,147 +427,44 @@ func escapeYAMLString(s string) string {
 
 // generateWorkflowCommands creates commands for workflow steps and orchestrators
 // Cursor supports /commands similar to Claude Code, so workflows map naturally to commands
-func (p *CursorProvider) generateWorkflowCommands(fs content.FileSystem, commandsDir string) error {
-	// Check if workflows directory exists
-	if _, err := fs.Stat("system/workflows"); err != nil {
-		// No workflows directory, skip silently
-		return nil
-	}
-
-	// Find all workflow folders (direct subdirectories)
-	entries, err := fs.ReadDir("system/workflows")
+func (p *CursorProvider) generateWorkflowCommands(fs content.FileSystem, plan *output.Plan, commandsDir string, config *wizard.Config) error {
+	workflows, err := loadWorkflows(fs, config)
 	if err != nil {
 		return err
 	}
 
-	for _, entry := range entries {
-		if !entry.IsDir() {
-			continue
-		}
-
-		workflowName := entry.Name()
-
-		// Generate commands for this workflow
-		if err := p.generateSingleWorkflowCommands(fs, commandsDir, workflowName); err != nil {
-			return fmt.Errorf("failed to generate workflow '%s': %w", workflowName, err)
+	for _, workflow := range workflows {
+		for _, step := range workflow.Steps {
+			p.createWorkflowStepCommand(plan, step, commandsDir)
 		}
+		p.createWorkflowOrchestratorCommand(plan, commandsDir, workflow)
 	}
-
 	return nil
 }
 
-// generateSingleWorkflowCommands creates step commands and an orchestrator for one workflow
-func (p *CursorProvider) generateSingleWorkflowCommands(fs content.FileSystem, commandsDir, workflowName string) error {
-	// Find all markdown files in the workflow directory
-	pattern := fmt.Sprintf("system/workflows/%s/*.md", workflowName)
-	files, err := fs.Glob(pattern)
-	if err != nil {
-		return err
-	}
-
-	if len(files) == 0 {
-		return nil
-	}
-
-	// Parse and sort workflow steps
-	steps := make([]templates.WorkflowStep, 0, len(files))
-	stepPattern := regexp.MustCompile(`^(\d+)[_-](.+)\.md$`)
-
-	for _, file := range files {
-		baseName := filepath.Base(file)
-		matches := stepPattern.FindStringSubmatch(baseName)
-
-		var order int
-		var stepName string
-
-		if matches != nil {
-			order, _ = strconv.Atoi(matches[1])
-			stepName = matches[2]
-		} else {
-			// No number prefix, use filename as-is
-			order = 99 // Put unnumbered files at end
-			stepName = strings.TrimSuffix(baseName, ".md")
-		}
-
-		// Normalize step name
-		stepName = strings.ReplaceAll(stepName, "_", "-")
-
-		// Generate the command name for this step
-		var commandName string
-		if matches != nil {
-			commandName = fmt.Sprintf("%s-%02d-%s", workflowName, order, stepName)
-		} else {
-			commandName = fmt.Sprintf("%s-%s", workflowName, stepName)
-		}
-
-		// Read file to extract description
-		fileContent, err := fs.ReadFile(file)
-		if err != nil {
-			return err
-		}
-
-		description := templates.ExtractStepDescription(string(fileContent))
-
-		steps = append(steps, templates.WorkflowStep{
-			Order:       order,
-			Name:        templates.NormalizeWorkflowName(stepName),
-			RuleName:    commandName, // Reusing RuleName field for command name
-			Description: description,
-		})
-
-		// Create the step command
-		if err := p.createWorkflowStepCommand(fs, file, commandsDir, commandName); err != nil {
-			return err
-		}
-	}
-
-	// Sort steps by order
-	sort.Slice(steps, func(i, j int) bool {
-		return steps[i].Order < steps[j].Order
-	})
-
-	// Create the workflow orchestrator command
-	return p.createWorkflowOrchestratorCommand(commandsDir, workflowName, steps)
-}
-
 // createWorkflowStepCommand creates a Cursor command for a single workflow step
 // Commands are simple markdown files without YAML frontmatter
-func (p *CursorProvider) createWorkflowStepCommand(fs content.FileSystem, sourcePath, commandsDir, commandName string) error {
-	fileContent, err := fs.ReadFile(sourcePath)
-	if err != nil {
-		return err
-	}
-
-	// Commands are flat markdown files - no YAML frontmatter needed
-	outputPath := filepath.Join(commandsDir, commandName+".md")
-	if err := os.WriteFile(outputPath, fileContent, 0644); err != nil {
-		return err
-	}
-
-	fmt.Printf("  Created: %s\n", outputPath)
-	return nil
+func (p *CursorProvider) createWorkflowStepCommand(plan *output.Plan, step Step, commandsDir string) {
+	plan.Add(output.File{
+		Path:      path.Join(commandsDir, step.Command+".md"),
+		Content:   step.Content,
+		Sources:   []string{step.Path},
+		Transform: "workflow step as a command",
+		Provider:  p.Name(),
+	})
 }
 
 // createWorkflowOrchestratorCommand creates the main workflow command that references all steps
-func (p *CursorProvider) createWorkflowOrchestratorCommand(commandsDir, workflowName string, steps []templates.WorkflowStep) error {
-	// Build the orchestrator data
-	data := templates.WorkflowOrchestratorData{
-		WorkflowName: workflowName,
-		DisplayName:  templates.NormalizeWorkflowName(workflowName),
-		Description:  fmt.Sprintf("Complete %s workflow with %d steps. Follow each step in order.", templates.NormalizeWorkflowName(workflowName), len(steps)),
-		Steps:        steps,
-	}
-
-	// Generate orchestrator content using the template
+func (p *CursorProvider) createWorkflowOrchestratorCommand(plan *output.Plan, commandsDir string, workflow Workflow) {
 	// For Cursor commands, use / to reference other commands
-	orchestratorContent := templates.GenerateWorkflowOrchestrator(data, "/")
-
-	// Write the command file (no YAML frontmatter for commands)
-	outputPath := filepath.Join(commandsDir, workflowName+".md")
-	if err := os.WriteFile(outputPath, []byte(orchestratorContent), 0644); err != nil {
-		return err
-	}
-
-	fmt.Printf("  Created: %s\n", outputPath)
-	return nil
+	orchestratorContent := workflow.Orchestrator("/", func(step Step) string { return step.Command })
+
+	// Plan the command file (no YAML frontmatter for commands)
+	plan.Add(output.File{
+		Path:      path.Join(commandsDir, workflow.Name+".md"),
+		Content:   []byte(orchestratorContent),
+		Sources:   workflow.Sources(),
+		Transform: "workflow orchestrator command listing the steps",
+		Provider:  p.Name(),
+	})
 }
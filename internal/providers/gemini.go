@@ -0,0 +1,260 @@
+package providers
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/agentspack/agentspack/internal/content"
+	"github.com/agentspack/agentspack/internal/output"
+	"github.com/agentspack/agentspack/internal/wizard"
+)
+
+func init() {
+	Register(&GeminiProvider{})
+}
+
+// GeminiProvider generates Gemini CLI files (GEMINI.md and custom commands)
+type GeminiProvider struct{}
+
+func (p *GeminiProvider) Name() string {
+	return "gemini"
+}
+
+func (p *GeminiProvider) DisplayName() string {
+	return "Gemini CLI"
+}
+
+func (p *GeminiProvider) Description() string {
+	return "GEMINI.md and custom commands in .gemini/commands/"
+}
+
+func (p *GeminiProvider) ContentKinds() []ContentKind {
+	return []ContentKind{KindBase, KindRules, KindWorkflows}
+}
+
+func (p *GeminiProvider) Options() []Option {
+	return []Option{
+		{
+			Key:         "gemini_context",
+			Title:       "Gemini CLI: Which context file should it read?",
+			Description: "GEMINI.md is Gemini's own; AGENTS.md is shared with Codex and Cursor, set through .gemini/settings.json",
+			Choices: []Choice{
+				{Value: string(wizard.GeminiContextGemini), Label: "GEMINI.md"},
+				{Value: string(wizard.GeminiContextAgents), Label: "AGENTS.md (shared with Codex and Cursor)"},
+			},
+			Get: func(config *wizard.Config) string { return string(config.GeminiContext) },
+			Set: func(config *wizard.Config, value string) { config.GeminiContext = wizard.GeminiContext(value) },
+		},
+	}
+}
+
+func (p *GeminiProvider) OwnedDirs() []string {
+	return []string{".gemini/commands"}
+}
+
+func (p *GeminiProvider) Generate(config *wizard.Config, fs content.FileSystem, plan *output.Plan) error {
+	// The .gemini directory structure
+	geminiDir := ".gemini"
+	commandsDir := path.Join(geminiDir, "commands")
+
+	// 0. Generate the context file with base content, global rules and tech
+	// stack rules. Personal context lives in ~/.gemini/GEMINI.md.
+	useAgents := config.GeminiContext == wizard.GeminiContextAgents
+	if useAgents && config.IsUserScope() {
+		plan.Warn(p.Name(), "the user scope always writes ~/.gemini/GEMINI.md; to read a shared AGENTS.md instead, set contextFileName in ~/.gemini/settings.json yourself")
+		useAgents = false
+	}
+	if err := p.generateContextFile(fs, plan, geminiDir, useAgents, config); err != nil {
+		return fmt.Errorf("failed to generate the context file: %w", err)
+	}
+
+	// 1. Generate workflows as custom commands
+	if err := p.generateWorkflowCommands(fs, plan, commandsDir, config); err != nil {
+		return fmt.Errorf("failed to generate workflow commands: %w", err)
+	}
+
+	// Gemini CLI has no agent definitions to convert agents to
+	agents, err := loadAgents(fs, config)
+	if err != nil {
+		return err
+	}
+	if len(agents) > 0 {
+		plan.Warn(p.Name(), fmt.Sprintf("Gemini CLI has no agent definitions, so %d agents were not installed", len(agents)))
+	}
+
+	return nil
+}
+
+// generateContextFile concatenates base.md, Gemini.md, the global rules and
+// the tech stack rules into GEMINI.md, or into Gemini's section of AGENTS.md
+// together with a .gemini/settings.json that points Gemini CLI at it
+func (p *GeminiProvider) generateContextFile(fs content.FileSystem, plan *output.Plan, geminiDir string, useAgents bool, config *wizard.Config) error {
+	rules, err := loadRules(fs, "global", p.Name(), config)
+	if err != nil {
+		return err
+	}
+
+	var contentBuilder strings.Builder
+	var sources []string
+	var shared []byte
+	if config.GenerateBase {
+		baseContent, err := fs.ReadFile("system/base/base.md")
+		if err != nil {
+			return fmt.Errorf("failed to read base.md: %w", err)
+		}
+		providerContent, err := fs.ReadFile("system/base/Gemini.md")
+		if err != nil {
+			return fmt.Errorf("failed to read Gemini.md: %w", err)
+		}
+
+		contentBuilder.Write(baseContent)
+		contentBuilder.WriteString("\n\n")
+		contentBuilder.Write(providerContent)
+		sources = append(sources, "system/base/base.md", "system/base/Gemini.md")
+		shared = baseContent
+	}
+
+	if len(rules) > 0 {
+		if contentBuilder.Len() > 0 {
+			contentBuilder.WriteString("\n\n---\n\n")
+		}
+		contentBuilder.WriteString("# Project Guidelines\n\n")
+		contentBuilder.WriteString("These guidelines apply to all work in this project.\n\n")
+		contentBuilder.WriteString(joinRules(rules, true))
+		sources = append(sources, rulePaths(rules)...)
+	}
+
+	// Gemini CLI loads its context file for every prompt and can't scope
+	// part of it to paths, so each stack's rules are a section that names
+	// the files they are meant for
+	selected, err := selectedStacks(config, fs)
+	if err != nil {
+		return err
+	}
+	for _, stack := range selected {
+		stackRules, err := loadRules(fs, stack.SourcePath, p.Name(), config)
+		if err != nil {
+			return fmt.Errorf("failed to load %s rules: %w", stack.Name, err)
+		}
+		if len(stackRules) == 0 {
+			continue
+		}
+
+		if contentBuilder.Len() > 0 {
+			contentBuilder.WriteString("\n\n---\n\n")
+		}
+		contentBuilder.WriteString(fmt.Sprintf("# %s Guidelines\n\n", stack.DisplayName))
+		if globs := resolveStackGlobs(config, stack.Name, stack.Globs); len(globs) > 0 {
+			contentBuilder.WriteString(fmt.Sprintf("These guidelines apply when working on files matching `%s`.\n\n", strings.Join(globs, "`, `")))
+		}
+		contentBuilder.WriteString(joinRules(stackRules, true))
+		sources = append(sources, rulePaths(stackRules)...)
+	}
+
+	if contentBuilder.Len() == 0 {
+		return nil
+	}
+
+	if !useAgents {
+		// Plan GEMINI.md as a managed block that keeps the project's own
+		// notes around it. With dedupe, it imports AGENTS.md instead of
+		// repeating the templates it holds.
+		contextPath := "GEMINI.md"
+		if config.IsUserScope() {
+			contextPath = path.Join(geminiDir, "GEMINI.md")
+		}
+		plan.Add(output.File{
+			Path:      contextPath,
+			Content:   []byte(contentBuilder.String()),
+			Sources:   sources,
+			Transform: "base.md, Gemini.md, global and tech stack rules concatenated",
+			Provider:  p.Name(),
+			Managed:   true,
+			Import:    "@%s",
+		})
+		return nil
+	}
+
+	// Plan Gemini's section of AGENTS.md, sharing base.md with the Codex
+	// and Cursor sections, and point Gemini CLI at it
+	plan.Add(output.File{
+		Path:      "AGENTS.md",
+		Content:   []byte(contentBuilder.String()),
+		Sources:   sources,
+		Transform: "base.md, Gemini.md, global and tech stack rules concatenated",
+		Provider:  p.Name(),
+		Managed:   true,
+		Merge:     output.MergeSections,
+		Shared:    shared,
+		Canonical: true,
+	})
+	settings, err := setGeminiContext(nil)
+	if err != nil {
+		return err
+	}
+	plan.Add(output.File{
+		Path:      path.Join(geminiDir, "settings.json"),
+		Content:   settings,
+		Transform: "settings pointing Gemini CLI at AGENTS.md",
+		Provider:  p.Name(),
+		Patch:     setGeminiContext,
+		Settings:  []output.Setting{{Key: "contextFileName", Item: "AGENTS.md"}},
+	})
+	return nil
+}
+
+// setGeminiContext points Gemini CLI's settings at AGENTS.md, keeping the
+// project's other settings (e.g. mcpServers)
+func setGeminiContext(settings []byte) ([]byte, error) {
+	return output.SetJSONKey(settings, "contextFileName", "AGENTS.md")
+}
+
+// generateWorkflowCommands creates a custom command for every workflow step
+// and an orchestrator command that runs them with /<command>
+func (p *GeminiProvider) generateWorkflowCommands(fs content.FileSystem, plan *output.Plan, commandsDir string, config *wizard.Config) error {
+	workflows, err := loadWorkflows(fs, config)
+	if err != nil {
+		return err
+	}
+
+	for _, workflow := range workflows {
+		for _, step := range workflow.Steps {
+			p.writeCommand(plan, commandsDir, step.Command, step.Description, string(step.Content), []string{step.Path}, "workflow step as a TOML custom command")
+		}
+
+		description := fmt.Sprintf("Run the %s workflow step by step", workflow.Name)
+		orchestrator := workflow.Orchestrator("/", func(step Step) string { return step.Command })
+		p.writeCommand(plan, commandsDir, workflow.Name, description, orchestrator, workflow.Sources(), "workflow orchestrator as a TOML custom command listing the steps")
+	}
+	return nil
+}
+
+// geminiInjections breaks up the sequences Gemini CLI expands in a
+// command's prompt (the arguments, shell output and file content), which
+// Gemini has no escape for
+var geminiInjections = strings.NewReplacer("{{args}}", "{ {args} }", "!{", "! {", "@{", "@ {")
+
+// writeCommand plans a custom command at <commandsDir>/<name>.toml, run
+// with /<name>
+func (p *GeminiProvider) writeCommand(plan *output.Plan, commandsDir, name, description, prompt string, sources []string, transform string) {
+	commandPath := path.Join(commandsDir, name+".toml")
+	if literal := geminiInjections.Replace(prompt); literal != prompt {
+		plan.Warn(p.Name(), fmt.Sprintf("%s holds {{args}}, !{ or @{, which Gemini CLI would run or expand; a space was added to keep them as text", commandPath))
+		prompt = literal
+	}
+
+	var command tomlBuilder
+	if description != "" {
+		command.Key("description", description)
+	}
+	command.MultilineKey("prompt", prompt)
+
+	plan.Add(output.File{
+		Path:      commandPath,
+		Content:   []byte(command.String()),
+		Sources:   sources,
+		Transform: transform,
+		Provider:  p.Name(),
+	})
+}
@@ -0,0 +1,139 @@
+package providers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/agentspack/agentspack/internal/output"
+	"github.com/agentspack/agentspack/internal/wizard"
+)
+
+func TestGeminiGenerate(t *testing.T) {
+	config := &wizard.Config{Providers: []string{"gemini"}, TechStacks: []string{"backend"}, GenerateBase: true, GeminiContext: wizard.GeminiContextGemini}
+	plan := output.NewPlan()
+	if err := (&GeminiProvider{}).Generate(config, writeTestTemplates(t), plan); err != nil {
+		t.Fatalf("Failed to generate: %v", err)
+	}
+
+	expected := map[string]string{
+		"GEMINI.md": "# Gemini\n",
+		".gemini/commands/planning-01-write-prd.toml": "description = \"Write the PRD.\"\nprompt = \"\"\"\n# PRD\n",
+		".gemini/commands/planning.toml":              "**Invoke**: /planning-02-write-todos\n",
+	}
+	for path, want := range expected {
+		f, ok := plan.Get(path)
+		if !ok {
+			t.Errorf("Expected %s to be planned", path)
+			continue
+		}
+		if !strings.Contains(string(f.Content), want) {
+			t.Errorf("Expected %s to contain %q, got:\n%s", path, want, f.Content)
+		}
+	}
+
+	gemini, _ := plan.Get("GEMINI.md")
+	if !strings.Contains(string(gemini.Content), "# Backend Guidelines\n\nThese guidelines apply when working on files matching `**/*.go`, `api/**`.") {
+		t.Errorf("Expected GEMINI.md to hold the backend rules, got:\n%s", gemini.Content)
+	}
+	if len(plan.Warnings()) != 1 {
+		t.Errorf("Expected a warning about the agent, got %v", plan.Warnings())
+	}
+
+	config.GeminiContext = wizard.GeminiContextAgents
+	plan = output.NewPlan()
+	if err := (&GeminiProvider{}).Generate(config, writeTestTemplates(t), plan); err != nil {
+		t.Fatalf("Failed to generate: %v", err)
+	}
+	if _, ok := plan.Get("GEMINI.md"); ok {
+		t.Error("Expected no GEMINI.md when Gemini CLI reads AGENTS.md")
+	}
+	if f, ok := plan.Get(".gemini/settings.json"); !ok || !strings.Contains(string(f.Content), `"contextFileName": "AGENTS.md"`) {
+		t.Errorf("Expected settings.json to point at AGENTS.md, got %q", f.Content)
+	}
+}
+
+func TestGeminiSettingsKeepOtherKeys(t *testing.T) {
+	config := &wizard.Config{Providers: []string{"gemini"}, GenerateBase: true, GeminiContext: wizard.GeminiContextAgents}
+	plan := output.NewPlan()
+	if err := (&GeminiProvider{}).Generate(config, writeTestTemplates(t), plan); err != nil {
+		t.Fatalf("Failed to generate: %v", err)
+	}
+	f, ok := plan.Get(".gemini/settings.json")
+	if !ok {
+		t.Fatal("Expected settings.json to be planned")
+	}
+
+	current := []byte(`{
+  "theme": "GitHub",
+  "contextFileName": "GEMINI.md",
+  "mcpServers": {"github": {"command": "gh-mcp", "args": ["--read-only"]}}
+}`)
+	settings, err := output.Render(f, current)
+	if err != nil {
+		t.Fatalf("Failed to render settings.json: %v", err)
+	}
+
+	want := `{
+  "theme": "GitHub",
+  "contextFileName": "AGENTS.md",
+  "mcpServers": {
+    "github": {
+      "command": "gh-mcp",
+      "args": [
+        "--read-only"
+      ]
+    }
+  }
+}
+`
+	if string(settings) != want {
+		t.Errorf("Expected the other settings to be kept, got:\n%s", settings)
+	}
+
+	if _, err := output.Render(f, []byte("[1, 2]")); err == nil {
+		t.Error("Expected settings that aren't a JSON object to be rejected")
+	}
+}
+
+func TestGeminiPromptsKeepInjectionsLiteral(t *testing.T) {
+	templates := map[string]string{
+		"system/rules/global/coding_style.md":    "## Coding style\n",
+		"system/workflows/release/01_publish.md": "# Publish\n\nTag {{args}}, then run !{git push} and read @{CHANGELOG.md}.\n",
+	}
+	config := &wizard.Config{Providers: []string{"gemini"}}
+	plan := output.NewPlan()
+	if err := (&GeminiProvider{}).Generate(config, writeTemplates(t, templates), plan); err != nil {
+		t.Fatalf("Failed to generate: %v", err)
+	}
+
+	f, ok := plan.Get(".gemini/commands/release-01-publish.toml")
+	if !ok {
+		t.Fatal("Expected the step command to be planned")
+	}
+	if !strings.Contains(string(f.Content), "Tag { {args} }, then run ! {git push} and read @ {CHANGELOG.md}.") {
+		t.Errorf("Expected the injections to be broken up, got:\n%s", f.Content)
+	}
+	// The orchestrator quotes the step's description
+	if len(plan.Warnings()) != 2 {
+		t.Errorf("Expected warnings about the step and the orchestrator, got %v", plan.Warnings())
+	}
+}
+
+func TestTOMLStrings(t *testing.T) {
+	tests := []struct {
+		quote func(string) string
+		in    string
+		want  string
+	}{
+		{tomlString, "say \"hi\"\n\\", `"say \"hi\"\n\\"`},
+		{tomlString, "bell\a", `"bell\u0007"`},
+		{tomlMultilineString, "a \"\"\" b\n", "\"\"\"\na \"\"\\\" b\n\"\"\""},
+		{tomlMultilineString, "ends with \"", "\"\"\"\nends with \\\"\"\"\""},
+		{tomlMultilineString, "C:\\dir\ttab", "\"\"\"\nC:\\\\dir\ttab\"\"\""},
+	}
+	for _, tt := range tests {
+		if got := tt.quote(tt.in); got != tt.want {
+			t.Errorf("Expected %q to be quoted as %s, got %s", tt.in, tt.want, got)
+		}
+	}
+}
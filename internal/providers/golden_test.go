@@ -0,0 +1,106 @@
+package providers
+
+import (
+	"bytes"
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/agentspack/agentspack/internal/output"
+	"github.com/agentspack/agentspack/internal/wizard"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+// TestGoldenOutput compares every file a provider plans for testTemplates
+// with testdata/golden/<name>, byte for byte. Run with -update after an
+// intended change to the output.
+func TestGoldenOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider Provider
+		config   wizard.Config
+	}{
+		{"claude-code-rules", &ClaudeCodeProvider{}, wizard.Config{ClaudeCodeMode: wizard.ClaudeCodeModeRules}},
+		{"claude-code-skills", &ClaudeCodeProvider{}, wizard.Config{ClaudeCodeMode: wizard.ClaudeCodeModeSkills}},
+		{"cursor", &CursorProvider{}, wizard.Config{}},
+		{"codex", &CodexProvider{}, wizard.Config{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config
+			config.Providers = []string{tt.provider.Name()}
+			config.TechStacks = []string{"backend"}
+			config.GenerateBase = true
+			plan := output.NewPlan()
+			if err := tt.provider.Generate(&config, writeTestTemplates(t), plan); err != nil {
+				t.Fatalf("Failed to generate: %v", err)
+			}
+
+			dir := filepath.Join("testdata", "golden", tt.name)
+			if *update {
+				if err := os.RemoveAll(dir); err != nil {
+					t.Fatal(err)
+				}
+				for _, f := range plan.Files() {
+					path := filepath.Join(dir, filepath.FromSlash(f.Path))
+					if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+						t.Fatal(err)
+					}
+					if err := os.WriteFile(path, f.Content, 0644); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+
+			golden, err := readGolden(dir)
+			if err != nil {
+				t.Fatalf("Failed to read the golden files: %v", err)
+			}
+			for _, f := range plan.Files() {
+				want, ok := golden[f.Path]
+				if !ok {
+					t.Errorf("Unexpected file %s", f.Path)
+					continue
+				}
+				if !bytes.Equal(f.Content, want) {
+					t.Errorf("%s differs from the golden file, got:\n%s", f.Path, f.Content)
+				}
+				delete(golden, f.Path)
+			}
+			var missing []string
+			for path := range golden {
+				missing = append(missing, path)
+			}
+			sort.Strings(missing)
+			for _, path := range missing {
+				t.Errorf("Expected %s to be planned", path)
+			}
+		})
+	}
+}
+
+// readGolden returns the files below dir by their slash-separated path
+func readGolden(dir string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = data
+		return nil
+	})
+	return files, err
+}
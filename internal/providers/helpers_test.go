@@ -0,0 +1,53 @@
+package providers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/agentspack/agentspack/internal/content"
+)
+
+// testTemplates is a small system directory with a base, a global rule, a
+// backend stack, an agent, a backend agent and a two-step workflow
+var testTemplates = map[string]string{
+	"system/base/base.md":                         "# Base\n",
+	"system/base/Aider.md":                        "# Aider\n",
+	"system/base/Claude.md":                       "# Claude\n",
+	"system/base/Cline.md":                        "# Cline\n",
+	"system/base/Codex.md":                        "# Codex\n",
+	"system/base/Copilot.md":                      "# Copilot\n",
+	"system/base/Cursor.md":                       "# Cursor\n",
+	"system/base/Gemini.md":                       "# Gemini\n",
+	"system/base/Windsurf.md":                     "# Windsurf\n",
+	"system/rules/global/coding_style.md":         "## Coding style\n",
+	"system/rules/backend/stack.yaml":             "name: backend\ndisplay_name: Backend\nglobs: [\"**/*.go\", \"api/**\"]\nrule_description: Backend API\n",
+	"system/rules/backend/database_queries.md":    "## Queries\n",
+	"system/rules/backend/migrations.md":          "---\nglobs: \"migrations/**\"\n---\n## Migrations\n",
+	"system/agents/ui_designer.md":                "---\nname: ui-designer\ndescription: Designs interfaces\n---\n\nYou design interfaces.\n",
+	"system/agents/backend/api_developer.md":      "---\nname: api-developer\ndescription: Builds APIs\ntools: Read, Grep, Bash\n---\n\nYou build APIs.\n\nWrite tests first.\n",
+	"system/workflows/planning/01_write_prd.md":   "# PRD\n\nWrite the PRD.\n",
+	"system/workflows/planning/02_write_todos.md": "# Todos\n\nWrite the todos.\n",
+}
+
+// writeTestTemplates writes testTemplates to a temp dir and returns it as a file system
+func writeTestTemplates(t *testing.T) content.FileSystem {
+	t.Helper()
+	return writeTemplates(t, testTemplates)
+}
+
+// writeTemplates writes the given templates to a temp dir and returns it as a file system
+func writeTemplates(t *testing.T, templates map[string]string) content.FileSystem {
+	t.Helper()
+	root := t.TempDir()
+	for name, data := range templates {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	return content.NewLocalFS(root)
+}
@@ -1,7 +1,11 @@
 package providers
 
 import (
+	"sort"
+
 	"github.com/agentspack/agentspack/internal/content"
+	"github.com/agentspack/agentspack/internal/output"
+	"github.com/agentspack/agentspack/internal/stacks"
 	"github.com/agentspack/agentspack/internal/wizard"
 )
 
@@ -10,10 +14,79 @@ type Provider interface {
 	// Name returns the provider identifier (e.g., "cursor", "claude-code")
 	Name() string
 
-	// Generate creates provider-specific output files based on the configuration
+	// Generate plans provider-specific output files based on the configuration
 	// fs is the filesystem to read templates from (embedded or local)
-	// outputDir is the base output directory
-	Generate(config *wizard.Config, fs content.FileSystem, outputDir string) error
+	// plan collects the files, with paths relative to the output directory
+	Generate(config *wizard.Config, fs content.FileSystem, plan *output.Plan) error
+}
+
+// ContentKind is a kind of output a provider can generate
+type ContentKind string
+
+const (
+	KindBase      ContentKind = "base"      // Base instructions file (CLAUDE.md, AGENTS.md, ...)
+	KindRules     ContentKind = "rules"     // Global and tech stack rules
+	KindSkills    ContentKind = "skills"    // On-demand skills
+	KindAgents    ContentKind = "agents"    // Agent definitions
+	KindWorkflows ContentKind = "workflows" // Workflow commands
+)
+
+// Describer is an optional interface for providers that describe themselves
+// to the wizard. Providers that don't implement it are listed by name.
+type Describer interface {
+	// DisplayName returns the human-readable name (e.g., "Claude Code")
+	DisplayName() string
+
+	// Description returns a one-line summary of what the provider generates
+	Description() string
+
+	// ContentKinds returns the kinds of content the provider can generate
+	ContentKinds() []ContentKind
+
+	// Options returns the provider-specific settings asked about when the provider is selected
+	Options() []Option
+}
+
+// DirOwner is an optional interface for providers that fully manage some
+// output directories. Files in them that a run no longer produces are
+// reported as orphaned.
+type DirOwner interface {
+	// OwnedDirs returns the managed directories, relative to the output directory
+	OwnedDirs() []string
+}
+
+// PlannedDirOwner is an optional interface for providers that also fully
+// manage directories named after the templates (e.g. a folder per agent),
+// which depend on what a run plans
+type PlannedDirOwner interface {
+	// PlannedDirs returns the managed directories of the plan, relative to
+	// the output directory
+	PlannedDirs(plan *output.Plan) []string
+}
+
+// Option is a provider-specific setting with a fixed set of choices
+type Option struct {
+	Key         string   // Config file key (e.g. "claude_code_mode")
+	Title       string   // Question shown in the wizard
+	Description string   // Help text shown in the wizard
+	Choices     []Choice // Allowed values, the first one is the default
+	Get         func(config *wizard.Config) string
+	Set         func(config *wizard.Config, value string)
+}
+
+// Choice is one allowed value of an Option
+type Choice struct {
+	Value string
+	Label string
+}
+
+// Info is the metadata of a registered provider
+type Info struct {
+	Name         string
+	DisplayName  string
+	Description  string
+	ContentKinds []ContentKind
+	Options      []Option
 }
 
 // Registry holds all available providers
@@ -29,3 +102,81 @@ func Get(name string) (Provider, bool) {
 	p, ok := Registry[name]
 	return p, ok
 }
+
+// Describe returns a provider's metadata, falling back to its name when it
+// doesn't implement Describer
+func Describe(p Provider) Info {
+	info := Info{Name: p.Name(), DisplayName: p.Name()}
+	if d, ok := p.(Describer); ok {
+		info.DisplayName = d.DisplayName()
+		info.Description = d.Description()
+		info.ContentKinds = d.ContentKinds()
+		info.Options = d.Options()
+	}
+	return info
+}
+
+// All returns the metadata of every registered provider, sorted by display name
+func All() []Info {
+	infos := make([]Info, 0, len(Registry))
+	for _, p := range Registry {
+		infos = append(infos, Describe(p))
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].DisplayName < infos[j].DisplayName
+	})
+	return infos
+}
+
+// OwnedDirs returns the directories managed by the named providers in a
+// run that planned plan
+func OwnedDirs(names []string, plan *output.Plan) []string {
+	var dirs []string
+	for _, name := range names {
+		if owner, ok := Registry[name].(DirOwner); ok {
+			dirs = append(dirs, owner.OwnedDirs()...)
+		}
+		if owner, ok := Registry[name].(PlannedDirOwner); ok {
+			dirs = append(dirs, owner.PlannedDirs(plan)...)
+		}
+	}
+	return dirs
+}
+
+// selectedStacks returns the stacks chosen in the config, in config order,
+// as declared by the stack manifests in fs. Unknown stacks are skipped.
+func selectedStacks(config *wizard.Config, fs content.FileSystem) ([]stacks.Stack, error) {
+	registry, err := stacks.Load(fs)
+	if err != nil {
+		return nil, err
+	}
+	return registry.Select(config.TechStacks), nil
+}
+
+// resolveStackGlobs returns the globs a tech stack's rules should be scoped to.
+// Inferred globs take the place of the provider defaults, and the user's
+// overrides are applied on top.
+func resolveStackGlobs(config *wizard.Config, stack string, defaults []string) []string {
+	globs := defaults
+	if inferred, ok := config.InferredGlobs[stack]; ok && len(inferred) > 0 {
+		globs = inferred
+	}
+
+	if override, ok := config.StackGlobs[stack]; ok {
+		if len(override.Replace) > 0 {
+			globs = override.Replace
+		}
+		globs = append(append([]string{}, globs...), override.Add...)
+	}
+
+	// Drop duplicates while keeping order
+	seen := make(map[string]bool, len(globs))
+	result := make([]string, 0, len(globs))
+	for _, glob := range globs {
+		if !seen[glob] {
+			seen[glob] = true
+			result = append(result, glob)
+		}
+	}
+	return result
+}
@@ -0,0 +1,223 @@
+package providers
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/agentspack/agentspack/internal/catalog"
+	"github.com/agentspack/agentspack/internal/content"
+	"github.com/agentspack/agentspack/internal/wizard"
+	"gopkg.in/yaml.v3"
+)
+
+// Rule is a single rule template under system/rules with its optional frontmatter
+type Rule struct {
+	Path        string   // Source path (e.g. system/rules/backend/database_queries.md)
+	Name        string   // File name without extension, underscores replaced (e.g. "database-queries")
+	Description string   // Overrides the description derived from the first heading
+	Globs       []string // Overrides the stack globs when non-empty
+	AlwaysApply *bool    // Overrides whether the rule is always loaded
+	Providers   []string // Limits the rule to these providers when non-empty
+	Stacks      []string // Limits the rule to runs selecting one of these stacks when non-empty
+	Order       int      // Rules are sorted by order, lowest first
+	Body        string   // Content without frontmatter
+}
+
+// ruleFrontmatter is the optional YAML frontmatter of a rule file
+type ruleFrontmatter struct {
+	Description string   `yaml:"description"`
+	Globs       globList `yaml:"globs"`
+	AlwaysApply *bool    `yaml:"alwaysApply"`
+	Providers   []string `yaml:"providers"`
+	Stacks      []string `yaml:"stacks"`
+	Order       int      `yaml:"order"`
+}
+
+// globList decodes either a list of globs or a single comma-separated string
+type globList []string
+
+func (g *globList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		var globs []string
+		for _, glob := range strings.Split(node.Value, ",") {
+			if glob = strings.TrimSpace(glob); glob != "" {
+				globs = append(globs, glob)
+			}
+		}
+		*g = globs
+		return nil
+	}
+	var list []string
+	if err := node.Decode(&list); err != nil {
+		return err
+	}
+	*g = list
+	return nil
+}
+
+// Scoped reports whether the rule declares its own globs or alwaysApply,
+// which means it has to be emitted on its own instead of merged with others
+func (r Rule) Scoped() bool {
+	return len(r.Globs) > 0 || r.AlwaysApply != nil
+}
+
+// IsAlwaysApply reports whether the rule should always be loaded, falling
+// back to def when the rule doesn't say
+func (r Rule) IsAlwaysApply(def bool) bool {
+	if r.AlwaysApply == nil {
+		return def
+	}
+	return *r.AlwaysApply
+}
+
+// DescriptionOr returns the rule's own description, or one extracted from
+// its first heading using prefix (or fallback when it has no heading)
+func (r Rule) DescriptionOr(prefix, fallback string) string {
+	if r.Description != "" {
+		return r.Description
+	}
+	return extractDescription(r.Body, prefix, fallback)
+}
+
+// loadRules reads the rule files in system/rules/<sourcePath> (and one level
+// of subdirectories) and returns those that apply to the provider, the
+// selected tech stacks and the rule selection, sorted by order (files without one keep their
+// position in the directory listing)
+func loadRules(fs content.FileSystem, sourcePath, provider string, config *wizard.Config) ([]Rule, error) {
+	files, err := fs.Glob(fmt.Sprintf("system/rules/%s/*.md", sourcePath))
+	if err != nil {
+		return nil, err
+	}
+	subFiles, err := fs.Glob(fmt.Sprintf("system/rules/%s/**/*.md", sourcePath))
+	if err == nil {
+		files = append(files, subFiles...)
+	}
+
+	var rules []Rule
+	for _, file := range files {
+		if !config.Rules.Allows(catalog.RuleID(file)) {
+			continue
+		}
+
+		data, err := fs.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+
+		rule, err := parseRule(file, string(data))
+		if err != nil {
+			return nil, err
+		}
+		if rule.appliesTo(provider, config.TechStacks) {
+			rules = append(rules, rule)
+		}
+	}
+
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].Order < rules[j].Order
+	})
+	return rules, nil
+}
+
+// parseRule splits a rule file into its frontmatter and body
+func parseRule(file, data string) (Rule, error) {
+	name := strings.TrimSuffix(path.Base(file), ".md")
+	rule := Rule{
+		Path: file,
+		Name: strings.ReplaceAll(name, "_", "-"),
+		Body: data,
+	}
+
+	frontmatter, body, ok := splitFrontmatter(data)
+	if !ok {
+		return rule, nil
+	}
+
+	var fm ruleFrontmatter
+	if err := yaml.Unmarshal([]byte(frontmatter), &fm); err != nil {
+		return Rule{}, fmt.Errorf("invalid frontmatter in %s: %w", file, err)
+	}
+
+	rule.Description = fm.Description
+	rule.Globs = fm.Globs
+	rule.AlwaysApply = fm.AlwaysApply
+	rule.Providers = fm.Providers
+	rule.Stacks = fm.Stacks
+	rule.Order = fm.Order
+	rule.Body = body
+	return rule, nil
+}
+
+// splitFrontmatter separates a leading "---" delimited frontmatter block from the body
+func splitFrontmatter(data string) (frontmatter, body string, ok bool) {
+	normalized := strings.ReplaceAll(data, "\r\n", "\n")
+	if !strings.HasPrefix(normalized, "---\n") {
+		return "", data, false
+	}
+
+	rest := normalized[len("---\n"):]
+	end := strings.Index(rest, "\n---")
+	if end == -1 {
+		return "", data, false
+	}
+
+	frontmatter = rest[:end]
+	body = rest[end+len("\n---"):]
+	body = strings.TrimLeft(strings.TrimPrefix(body, "-"), "\n")
+	return frontmatter, body, true
+}
+
+// appliesTo checks the rule's providers and stacks filters
+func (r Rule) appliesTo(provider string, selectedStacks []string) bool {
+	if len(r.Providers) > 0 && !contains(r.Providers, provider) {
+		return false
+	}
+	if len(r.Stacks) == 0 {
+		return true
+	}
+	for _, stack := range r.Stacks {
+		if contains(selectedStacks, stack) {
+			return true
+		}
+	}
+	return false
+}
+
+// joinRules concatenates rule bodies with separators. When withScope is set,
+// rules with their own globs are introduced by a note listing them, for
+// outputs like skills that cannot scope individual sections.
+func joinRules(rules []Rule, withScope bool) string {
+	var b strings.Builder
+	for i, rule := range rules {
+		if i > 0 {
+			b.WriteString("\n---\n\n")
+		}
+		if withScope && len(rule.Globs) > 0 {
+			b.WriteString(fmt.Sprintf("_Applies to files matching: %s_\n\n", strings.Join(rule.Globs, ", ")))
+		}
+		b.WriteString(rule.Body)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// rulePaths returns the source paths of the rules
+func rulePaths(rules []Rule) []string {
+	paths := make([]string, len(rules))
+	for i, rule := range rules {
+		paths[i] = rule.Path
+	}
+	return paths
+}
+
+// contains checks if a string is in the list
+func contains(items []string, target string) bool {
+	for _, item := range items {
+		if item == target {
+			return true
+		}
+	}
+	return false
+}
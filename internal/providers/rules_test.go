@@ -0,0 +1,78 @@
+package providers
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/agentspack/agentspack/internal/content"
+	"github.com/agentspack/agentspack/internal/wizard"
+)
+
+func TestParseRuleFrontmatter(t *testing.T) {
+	data := "---\ndescription: Repository queries\nglobs: \"**/repositories/**, **/*.sql\"\nalwaysApply: false\norder: 2\n---\n\n## Queries\n"
+
+	rule, err := parseRule("system/rules/backend/database_queries.md", data)
+	if err != nil {
+		t.Fatalf("Failed to parse rule: %v", err)
+	}
+
+	if rule.Name != "database-queries" || rule.Description != "Repository queries" || rule.Order != 2 {
+		t.Errorf("Unexpected rule metadata: %+v", rule)
+	}
+	if !reflect.DeepEqual(rule.Globs, []string{"**/repositories/**", "**/*.sql"}) {
+		t.Errorf("Unexpected globs: %v", rule.Globs)
+	}
+	if rule.AlwaysApply == nil || *rule.AlwaysApply || !rule.Scoped() {
+		t.Errorf("Expected explicit alwaysApply: false, got %v", rule.AlwaysApply)
+	}
+	if rule.Body != "## Queries\n" {
+		t.Errorf("Frontmatter not stripped from body: %q", rule.Body)
+	}
+}
+
+func TestParseRuleWithoutFrontmatter(t *testing.T) {
+	data := "## Components\n\n---\n\nMore\n"
+
+	rule, err := parseRule("system/rules/frontend/react/writing_components.md", data)
+	if err != nil {
+		t.Fatalf("Failed to parse rule: %v", err)
+	}
+	if rule.Body != data || rule.Scoped() {
+		t.Errorf("Expected rule to be left untouched, got %+v", rule)
+	}
+}
+
+func TestLoadRulesFiltersAndOrders(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"a_first.md":    "## A\n",
+		"b_cursor.md":   "---\nproviders: [cursor]\n---\n## B\n",
+		"c_react.md":    "---\nstacks: [react]\n---\n## C\n",
+		"d_earliest.md": "---\norder: -1\n---\n## D\n",
+	}
+	for name, data := range files {
+		path := filepath.Join(root, "system", "rules", "global", name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	config := &wizard.Config{TechStacks: []string{"backend"}}
+	rules, err := loadRules(content.NewLocalFS(root), "global", "codex", config)
+	if err != nil {
+		t.Fatalf("Failed to load rules: %v", err)
+	}
+
+	var names []string
+	for _, rule := range rules {
+		names = append(names, rule.Name)
+	}
+	if want := []string{"d-earliest", "a-first"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Unexpected rules: got %v, want %v", names, want)
+	}
+}
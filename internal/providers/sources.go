@@ -0,0 +1,193 @@
+package providers
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/agentspack/agentspack/internal/catalog"
+	"github.com/agentspack/agentspack/internal/content"
+	"github.com/agentspack/agentspack/internal/templates"
+	"github.com/agentspack/agentspack/internal/wizard"
+)
+
+// stepPattern matches numbered workflow step files (e.g. 01_create_prd.md)
+var stepPattern = regexp.MustCompile(`^(\d+)[_-](.+)\.md$`)
+
+// fieldPattern matches a "key: value" line of agent frontmatter
+var fieldPattern = regexp.MustCompile(`^([A-Za-z][\w-]*):\s*(.*)$`)
+
+// Agent is an agent template under system/agents
+type Agent struct {
+	Path        string            // Source path (e.g. system/agents/ui-designer.md)
+	Name        string            // From the frontmatter or the file name, underscores replaced (e.g. "ui-designer")
+	Description string            // From the frontmatter, empty when it has none
+	Body        string            // Content without frontmatter
+	Fields      map[string]string // Single-line frontmatter fields by key (e.g. "tools": "Read, Grep")
+}
+
+// Workflow is a folder of step templates under system/workflows
+type Workflow struct {
+	Name  string // Folder name (e.g. "planning")
+	Steps []Step // Sorted by order
+}
+
+// Step is one step template of a workflow
+type Step struct {
+	Path        string // Source path (e.g. system/workflows/planning/01_create_prd.md)
+	Order       int    // Number prefix of the file name, 99 without one
+	Name        string // File name without number prefix and extension, underscores replaced (e.g. "create-prd")
+	Command     string // Workflow, order and name (e.g. "planning-01-create-prd")
+	Description string // First paragraph of the step
+	Content     []byte
+}
+
+// loadAgents reads the agent templates in system/agents and its
+// subdirectories that the agent selection allows
+func loadAgents(fs content.FileSystem, config *wizard.Config) ([]Agent, error) {
+	files, err := catalog.AgentFiles(fs)
+	if err != nil {
+		return nil, err
+	}
+
+	var agents []Agent
+	for _, file := range files {
+		if !config.Agents.Allows(catalog.AgentID(file)) {
+			continue
+		}
+
+		data, err := fs.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+
+		name, description, body := parseAgentFrontmatter(string(data))
+		if name == "" {
+			name = strings.TrimSuffix(filepath.Base(file), ".md")
+		}
+		agents = append(agents, Agent{
+			Path:        file,
+			Name:        strings.ReplaceAll(name, "_", "-"),
+			Description: description,
+			Body:        body,
+			Fields:      agentFields(string(data)),
+		})
+	}
+	return agents, nil
+}
+
+// agentFields returns the single-line fields of an agent's frontmatter.
+// Agent frontmatter isn't always valid YAML, so it is read line by line.
+func agentFields(data string) map[string]string {
+	frontmatter, _, ok := splitFrontmatter(data)
+	if !ok {
+		return nil
+	}
+
+	fields := make(map[string]string)
+	for _, line := range strings.Split(frontmatter, "\n") {
+		matches := fieldPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if matches != nil && matches[2] != "" {
+			fields[matches[1]] = strings.Trim(matches[2], `"'`)
+		}
+	}
+	return fields
+}
+
+// loadWorkflows reads the workflows in system/workflows that the workflow
+// selection allows, skipping folders without steps
+func loadWorkflows(fs content.FileSystem, config *wizard.Config) ([]Workflow, error) {
+	if _, err := fs.Stat("system/workflows"); err != nil {
+		return nil, nil
+	}
+
+	entries, err := fs.ReadDir("system/workflows")
+	if err != nil {
+		return nil, err
+	}
+
+	var workflows []Workflow
+	for _, entry := range entries {
+		if !entry.IsDir() || !config.Workflows.Allows(catalog.WorkflowID(entry.Name())) {
+			continue
+		}
+
+		steps, err := loadSteps(fs, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read workflow '%s': %w", entry.Name(), err)
+		}
+		if len(steps) > 0 {
+			workflows = append(workflows, Workflow{Name: entry.Name(), Steps: steps})
+		}
+	}
+	return workflows, nil
+}
+
+// loadSteps reads the step templates of a workflow, sorted by order
+func loadSteps(fs content.FileSystem, workflowName string) ([]Step, error) {
+	files, err := fs.Glob(fmt.Sprintf("system/workflows/%s/*.md", workflowName))
+	if err != nil {
+		return nil, err
+	}
+
+	steps := make([]Step, 0, len(files))
+	for _, file := range files {
+		data, err := fs.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		baseName := filepath.Base(file)
+		step := Step{Path: file, Order: 99, Name: strings.TrimSuffix(baseName, ".md"), Content: data}
+		matches := stepPattern.FindStringSubmatch(baseName)
+		if matches != nil {
+			step.Order, _ = strconv.Atoi(matches[1])
+			step.Name = matches[2]
+		}
+		step.Name = strings.ReplaceAll(step.Name, "_", "-")
+
+		step.Command = fmt.Sprintf("%s-%s", workflowName, step.Name)
+		if matches != nil {
+			step.Command = fmt.Sprintf("%s-%02d-%s", workflowName, step.Order, step.Name)
+		}
+		step.Description = templates.ExtractStepDescription(string(data))
+		steps = append(steps, step)
+	}
+
+	sort.SliceStable(steps, func(i, j int) bool {
+		return steps[i].Order < steps[j].Order
+	})
+	return steps, nil
+}
+
+// Sources returns the source paths of the workflow's steps
+func (w Workflow) Sources() []string {
+	paths := make([]string, len(w.Steps))
+	for i, step := range w.Steps {
+		paths[i] = step.Path
+	}
+	return paths
+}
+
+// Orchestrator returns the content of the workflow's orchestrator, which
+// invokes each step as prefix followed by the step's reference name
+func (w Workflow) Orchestrator(prefix string, reference func(Step) string) string {
+	displayName := templates.NormalizeWorkflowName(w.Name)
+	data := templates.WorkflowOrchestratorData{
+		WorkflowName: w.Name,
+		DisplayName:  displayName,
+		Description:  fmt.Sprintf("Complete %s workflow with %d steps. Follow each step in order.", displayName, len(w.Steps)),
+	}
+	for _, step := range w.Steps {
+		data.Steps = append(data.Steps, templates.WorkflowStep{
+			Order:       step.Order,
+			Name:        templates.NormalizeWorkflowName(step.Name),
+			RuleName:    reference(step),
+			Description: step.Description,
+		})
+	}
+	return templates.GenerateWorkflowOrchestrator(data, prefix)
+}
@@ -0,0 +1,53 @@
+package providers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/agentspack/agentspack/internal/wizard"
+)
+
+func TestLoadAgentsFindsNestedFolders(t *testing.T) {
+	fs := writeTemplates(t, map[string]string{
+		"system/agents/ui_designer.md":                     "# UI\n",
+		"system/agents/backend/api_developer.md":           "# API\n",
+		"system/agents/backend/payments/billing_expert.md": "---\nname: billing-expert\ndescription: Bills\n---\n\n# Billing\n",
+	})
+
+	agents, err := loadAgents(fs, &wizard.Config{})
+	if err != nil {
+		t.Fatalf("Failed to load agents: %v", err)
+	}
+
+	var names []string
+	for _, agent := range agents {
+		names = append(names, agent.Name)
+	}
+	if want := []string{"ui-designer", "api-developer", "billing-expert"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Expected agents %v, got %v", want, names)
+	}
+}
+
+func TestLoadWorkflowsSortsSteps(t *testing.T) {
+	fs := writeTemplates(t, map[string]string{
+		"system/workflows/planning/10_review.md":    "# Review\n",
+		"system/workflows/planning/02_write_prd.md": "# PRD\n\nWrite the PRD.\n",
+		"system/workflows/planning/notes.md":        "# Notes\n",
+	})
+
+	workflows, err := loadWorkflows(fs, &wizard.Config{})
+	if err != nil {
+		t.Fatalf("Failed to load workflows: %v", err)
+	}
+	if len(workflows) != 1 {
+		t.Fatalf("Expected one workflow, got %d", len(workflows))
+	}
+
+	var commands []string
+	for _, step := range workflows[0].Steps {
+		commands = append(commands, step.Command)
+	}
+	if want := []string{"planning-02-write-prd", "planning-10-review", "planning-notes"}; !reflect.DeepEqual(commands, want) {
+		t.Errorf("Expected steps %v, got %v", want, commands)
+	}
+}
@@ -0,0 +1,98 @@
+package providers
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tomlBuilder writes a TOML document of string keys, the TOML counterpart
+// of the frontmatter the markdown outputs are built with
+type tomlBuilder struct {
+	strings.Builder
+}
+
+// Key writes key = "value" on one line
+func (b *tomlBuilder) Key(key, value string) {
+	b.WriteString(fmt.Sprintf("%s = %s\n", key, tomlString(value)))
+}
+
+// MultilineKey writes key = """value""" with value on the lines that follow
+func (b *tomlBuilder) MultilineKey(key, value string) {
+	b.WriteString(fmt.Sprintf("%s = %s\n", key, tomlMultilineString(value)))
+}
+
+// tomlString quotes s as a TOML basic string
+func tomlString(s string) string {
+	var quoted strings.Builder
+	quoted.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			quoted.WriteString(`\"`)
+		case '\\':
+			quoted.WriteString(`\\`)
+		case '\n':
+			quoted.WriteString(`\n`)
+		case '\t':
+			quoted.WriteString(`\t`)
+		case '\r':
+			quoted.WriteString(`\r`)
+		default:
+			writeTOMLRune(&quoted, r)
+		}
+	}
+	quoted.WriteByte('"')
+	return quoted.String()
+}
+
+// tomlMultilineString quotes s as a TOML multi-line basic string. The
+// newline after the opening quotes isn't part of the value.
+func tomlMultilineString(s string) string {
+	var quoted strings.Builder
+	quoted.WriteString("\"\"\"\n")
+	quotes := 0
+	for _, r := range s {
+		if r == '"' {
+			// Three quotes in a row would end the string
+			quotes++
+			if quotes == 3 {
+				quoted.WriteString(`\"`)
+				quotes = 0
+				continue
+			}
+			quoted.WriteRune(r)
+			continue
+		}
+		quotes = 0
+
+		switch r {
+		case '\\':
+			quoted.WriteString(`\\`)
+		case '\n', '\t':
+			quoted.WriteRune(r)
+		case '\r':
+			quoted.WriteString(`\r`)
+		default:
+			writeTOMLRune(&quoted, r)
+		}
+	}
+	if quotes > 0 {
+		// Escape quotes just before the closing ones so they don't run into them
+		content := strings.TrimSuffix(quoted.String(), strings.Repeat(`"`, quotes))
+		quoted.Reset()
+		quoted.WriteString(content)
+		quoted.WriteString(strings.Repeat(`\"`, quotes))
+	}
+	quoted.WriteString(`"""`)
+	return quoted.String()
+}
+
+// writeTOMLRune writes r, escaping the control characters TOML strings
+// can't hold
+func writeTOMLRune(b *strings.Builder, r rune) {
+	if r < 0x20 || r == 0x7f {
+		b.WriteString(fmt.Sprintf(`\u%04X`, r))
+		return
+	}
+	b.WriteRune(r)
+}
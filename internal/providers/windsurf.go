@@ -0,0 +1,455 @@
+package providers
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/agentspack/agentspack/internal/content"
+	"github.com/agentspack/agentspack/internal/output"
+	"github.com/agentspack/agentspack/internal/provenance"
+	"github.com/agentspack/agentspack/internal/stacks"
+	"github.com/agentspack/agentspack/internal/version"
+	"github.com/agentspack/agentspack/internal/wizard"
+)
+
+// Windsurf ignores whatever a rule or workflow file holds past its
+// character limit; the global rules file has a lower one
+const (
+	windsurfFileLimit        = 12000
+	windsurfGlobalRulesLimit = 6000
+)
+
+// Windsurf rule activation modes
+const (
+	triggerAlwaysOn      = "always_on"
+	triggerGlob          = "glob"
+	triggerModelDecision = "model_decision"
+)
+
+func init() {
+	Register(&WindsurfProvider{})
+}
+
+// WindsurfProvider generates Windsurf rules and workflows
+type WindsurfProvider struct{}
+
+func (p *WindsurfProvider) Name() string {
+	return "windsurf"
+}
+
+func (p *WindsurfProvider) DisplayName() string {
+	return "Windsurf"
+}
+
+func (p *WindsurfProvider) Description() string {
+	return "Always-on, glob and model-decision rules and workflows in .windsurf/"
+}
+
+func (p *WindsurfProvider) ContentKinds() []ContentKind {
+	return []ContentKind{KindBase, KindRules, KindAgents, KindWorkflows}
+}
+
+func (p *WindsurfProvider) Options() []Option {
+	return nil
+}
+
+func (p *WindsurfProvider) OwnedDirs() []string {
+	return []string{".windsurf/rules", ".windsurf/workflows"}
+}
+
+func (p *WindsurfProvider) Generate(config *wizard.Config, fs content.FileSystem, plan *output.Plan) error {
+	// Windsurf only reads global rules from the home directory
+	if config.IsUserScope() {
+		if err := p.generateGlobalRulesFile(fs, plan, config); err != nil {
+			return fmt.Errorf("failed to generate global rules: %w", err)
+		}
+		plan.Warn(p.Name(), "Windsurf only reads rules and workflows from the workspace, so tech stack rules, agents and workflows were not installed")
+		return nil
+	}
+
+	// The .windsurf directory structure
+	rulesDir := path.Join(".windsurf", "rules")
+	workflowsDir := path.Join(".windsurf", "workflows")
+
+	// 0. Generate the base rule if requested
+	if config.GenerateBase {
+		baseContent, sources, err := p.readBase(fs)
+		if err != nil {
+			return err
+		}
+		p.writeRule(plan, config, rulesDir, "base", triggerAlwaysOn, "", nil, baseContent, sources, "base.md and Windsurf.md concatenated into an always-on rule")
+	}
+
+	// 1. Generate global rules as always-on rules
+	if err := p.generateGlobalRules(fs, plan, rulesDir, config); err != nil {
+		return fmt.Errorf("failed to generate global rules: %w", err)
+	}
+
+	// 2. Generate tech stack rules as glob-triggered rules
+	selected, err := selectedStacks(config, fs)
+	if err != nil {
+		return err
+	}
+	for _, stack := range selected {
+		stack.Globs = resolveStackGlobs(config, stack.Name, stack.Globs)
+		if err := p.generateStackRules(fs, plan, rulesDir, stack, config); err != nil {
+			return fmt.Errorf("failed to generate %s rules: %w", stack.Name, err)
+		}
+	}
+
+	// 3. Generate agents as model-decision rules
+	agents, err := loadAgents(fs, config)
+	if err != nil {
+		return fmt.Errorf("failed to generate agent rules: %w", err)
+	}
+	for _, agent := range agents {
+		description := agent.Description
+		if description == "" {
+			description = fmt.Sprintf("Agent: %s", agent.Name)
+		}
+		p.writeRule(plan, config, rulesDir, "agent-"+agent.Name, triggerModelDecision, description, nil, agent.Body, []string{agent.Path}, "agent converted to a model-decision rule")
+	}
+
+	// 4. Generate workflows
+	if err := p.generateWorkflows(fs, plan, workflowsDir, config); err != nil {
+		return fmt.Errorf("failed to generate workflows: %w", err)
+	}
+
+	return nil
+}
+
+// readBase concatenates base.md and Windsurf.md
+func (p *WindsurfProvider) readBase(fs content.FileSystem) (string, []string, error) {
+	baseContent, err := fs.ReadFile("system/base/base.md")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read base.md: %w", err)
+	}
+	providerContent, err := fs.ReadFile("system/base/Windsurf.md")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read Windsurf.md: %w", err)
+	}
+	return string(baseContent) + "\n\n" + string(providerContent), []string{"system/base/base.md", "system/base/Windsurf.md"}, nil
+}
+
+// generateGlobalRulesFile concatenates base.md, Windsurf.md and the global
+// rules into ~/.codeium/windsurf/memories/global_rules.md
+func (p *WindsurfProvider) generateGlobalRulesFile(fs content.FileSystem, plan *output.Plan, config *wizard.Config) error {
+	rules, err := loadRules(fs, "global", p.Name(), config)
+	if err != nil {
+		return err
+	}
+
+	var contentBuilder strings.Builder
+	var sources []string
+	if config.GenerateBase {
+		baseContent, baseSources, err := p.readBase(fs)
+		if err != nil {
+			return err
+		}
+		contentBuilder.WriteString(baseContent)
+		sources = baseSources
+	}
+	if len(rules) > 0 {
+		if contentBuilder.Len() > 0 {
+			contentBuilder.WriteString("\n\n---\n\n")
+		}
+		contentBuilder.WriteString(joinRules(rules, true))
+	}
+	if contentBuilder.Len() == 0 {
+		return nil
+	}
+
+	file := output.File{
+		Path:      path.Join(".codeium", "windsurf", "memories", "global_rules.md"),
+		Content:   []byte(contentBuilder.String()),
+		Sources:   append(sources, rulePaths(rules)...),
+		Transform: "base.md, Windsurf.md and global rules concatenated into global rules",
+		Provider:  p.Name(),
+	}
+	if n := utf8.RuneCountInString(contentBuilder.String()) + headerLength(config, file); n > windsurfGlobalRulesLimit {
+		plan.Warn(p.Name(), fmt.Sprintf("%s has %d characters, over Windsurf's limit of %d for global rules; Windsurf ignores the rest", file.Path, n, windsurfGlobalRulesLimit))
+	}
+
+	plan.Add(file)
+	return nil
+}
+
+// generateGlobalRules creates an always-on global.md with all global rules,
+// plus a rule for each global rule with its own scope
+func (p *WindsurfProvider) generateGlobalRules(fs content.FileSystem, plan *output.Plan, rulesDir string, config *wizard.Config) error {
+	rules, err := loadRules(fs, "global", p.Name(), config)
+	if err != nil {
+		return err
+	}
+
+	var merged []Rule
+	for _, rule := range rules {
+		if !rule.Scoped() {
+			merged = append(merged, rule)
+			continue
+		}
+		ruleName := "global-" + rule.Name
+		trigger := triggerGlob
+		if rule.IsAlwaysApply(len(rule.Globs) == 0) {
+			trigger = triggerAlwaysOn
+		}
+		p.writeRule(plan, config, rulesDir, ruleName, trigger, rule.DescriptionOr("Global", ruleName), rule.Globs, rule.Body, []string{rule.Path}, "rule with an activation mode")
+	}
+
+	if len(merged) == 0 {
+		return nil
+	}
+
+	var body strings.Builder
+	body.WriteString("# Global Coding Standards\n\n")
+	body.WriteString("These rules apply to all files in the project.\n\n")
+	body.WriteString(joinRules(merged, false))
+
+	p.writeRule(plan, config, rulesDir, "global", triggerAlwaysOn, "", nil, body.String(), rulePaths(merged), "global rules joined into an always-on rule")
+	return nil
+}
+
+// generateStackRules creates a glob-triggered <stack>.md. Rules with their
+// own globs or alwaysApply get a rule of their own.
+func (p *WindsurfProvider) generateStackRules(fs content.FileSystem, plan *output.Plan, rulesDir string, stack stacks.Stack, config *wizard.Config) error {
+	rules, err := loadRules(fs, stack.SourcePath, p.Name(), config)
+	if err != nil {
+		return err
+	}
+
+	var merged []Rule
+	for _, rule := range rules {
+		if !rule.Scoped() {
+			merged = append(merged, rule)
+			continue
+		}
+
+		ruleName := fmt.Sprintf("%s-%s", stack.Name, rule.Name)
+		globs := stack.Globs
+		if len(rule.Globs) > 0 {
+			globs = rule.Globs
+		}
+		trigger := triggerGlob
+		if rule.IsAlwaysApply(false) {
+			trigger = triggerAlwaysOn
+		}
+		p.writeRule(plan, config, rulesDir, ruleName, trigger, rule.DescriptionOr(stack.RuleDescription, ruleName), globs, rule.Body, []string{rule.Path}, "rule with an activation mode")
+	}
+
+	if len(merged) == 0 {
+		return nil
+	}
+
+	var body strings.Builder
+	body.WriteString(fmt.Sprintf("# %s Guidelines\n\n", stack.DisplayName))
+	body.WriteString(joinRules(merged, false))
+
+	p.writeRule(plan, config, rulesDir, stack.Name, triggerGlob, fmt.Sprintf("%s development guidelines", stack.DisplayName), stack.Globs, body.String(), rulePaths(merged), "stack rules joined into a glob-triggered rule")
+	return nil
+}
+
+// writeRule plans a Windsurf rule at <rulesDir>/<ruleName>.md with the given
+// activation mode. A rule over the character limit is split into parts at
+// its headings, <ruleName>-part-2.md and so on, each with the same trigger.
+func (p *WindsurfProvider) writeRule(plan *output.Plan, config *wizard.Config, rulesDir, ruleName, trigger, description string, globs []string, body string, sources []string, transform string) {
+	frontmatter := func(part, parts int) string {
+		var fm strings.Builder
+		fm.WriteString("---\n")
+		fm.WriteString(fmt.Sprintf("trigger: %s\n", trigger))
+		switch trigger {
+		case triggerModelDecision:
+			partDescription := description
+			if parts > 1 {
+				partDescription = fmt.Sprintf("%s (part %d of %d)", strings.TrimSuffix(description, "."), part, parts)
+			}
+			fm.WriteString(fmt.Sprintf("description: \"%s\"\n", escapeYAMLString(partDescription)))
+		case triggerGlob:
+			fm.WriteString(fmt.Sprintf("globs: %s\n", strings.Join(globs, ", ")))
+		}
+		fm.WriteString("---\n\n")
+		return fm.String()
+	}
+
+	file := output.File{Sources: sources, Transform: transform, Provider: p.Name()}
+	overhead := utf8.RuneCountInString(frontmatter(1, 1)) + headerLength(config, file) + windsurfPartOverhead
+	parts := p.split(plan, path.Join(rulesDir, ruleName+".md"), body, overhead)
+	for i, part := range parts {
+		name := ruleName
+		if i > 0 {
+			name = fmt.Sprintf("%s-part-%d", ruleName, i+1)
+			file.PartOf = path.Join(rulesDir, ruleName+".md")
+		}
+		file.Path = path.Join(rulesDir, name+".md")
+		file.Content = []byte(frontmatter(i+1, len(parts)) + part)
+		plan.Add(file)
+	}
+}
+
+// generateWorkflows creates a workflow for every workflow step and an
+// orchestrator workflow that calls them with /<workflow>
+func (p *WindsurfProvider) generateWorkflows(fs content.FileSystem, plan *output.Plan, workflowsDir string, config *wizard.Config) error {
+	workflows, err := loadWorkflows(fs, config)
+	if err != nil {
+		return err
+	}
+
+	for _, workflow := range workflows {
+		for _, step := range workflow.Steps {
+			p.writeWorkflow(plan, config, workflowsDir, step.Command, step.Description, string(step.Content), []string{step.Path}, "workflow step as a workflow")
+		}
+
+		description := fmt.Sprintf("Run the %s workflow step by step", workflow.Name)
+		orchestrator := workflow.Orchestrator("/", func(step Step) string { return step.Command })
+		p.writeWorkflow(plan, config, workflowsDir, workflow.Name, description, orchestrator, workflow.Sources(), "workflow orchestrator calling the step workflows")
+	}
+	return nil
+}
+
+// writeWorkflow plans a Windsurf workflow at <workflowsDir>/<name>.md. A
+// workflow over the character limit is split into parts at its headings,
+// each ending with a call to the next part.
+func (p *WindsurfProvider) writeWorkflow(plan *output.Plan, config *wizard.Config, workflowsDir, name, description, body string, sources []string, transform string) {
+	frontmatter := "---\n"
+	if description != "" {
+		frontmatter += fmt.Sprintf("description: \"%s\"\n", escapeYAMLString(description))
+	}
+	frontmatter += "---\n\n"
+
+	file := output.File{Sources: sources, Transform: transform, Provider: p.Name()}
+	overhead := utf8.RuneCountInString(frontmatter) + headerLength(config, file) + windsurfPartOverhead
+	parts := p.split(plan, path.Join(workflowsDir, name+".md"), body, overhead)
+	partName := func(i int) string {
+		if i == 0 {
+			return name
+		}
+		return fmt.Sprintf("%s-part-%d", name, i+1)
+	}
+	for i, part := range parts {
+		if i < len(parts)-1 {
+			part = strings.TrimRight(part, "\n") + fmt.Sprintf("\n\nThis workflow continues in its next part: call /%s\n", partName(i+1))
+		}
+		if i > 0 {
+			file.PartOf = path.Join(workflowsDir, name+".md")
+		}
+		file.Path = path.Join(workflowsDir, partName(i)+".md")
+		file.Content = []byte(frontmatter + part)
+		plan.Add(file)
+	}
+}
+
+// windsurfPartOverhead is room left in every part for what is added around
+// the body, like the call to the next part
+const windsurfPartOverhead = 200
+
+// headerLength is how many characters the provenance header the generator
+// adds to f takes, or 0 without --provenance. Template hashes have a fixed
+// length, so the header doesn't depend on the templates' content.
+func headerLength(config *wizard.Config, f output.File) int {
+	if !config.Provenance {
+		return 0
+	}
+	// One more for the blank line after it in files without frontmatter
+	return utf8.RuneCountInString(provenance.Header(f, nil, version.Version)) + 1
+}
+
+// split splits a body that doesn't fit Windsurf's character limit next to
+// overhead characters of frontmatter, and warns about it
+func (p *WindsurfProvider) split(plan *output.Plan, filePath, body string, overhead int) []string {
+	parts := splitMarkdown(body, windsurfFileLimit-overhead)
+	if len(parts) > 1 {
+		plan.Warn(p.Name(), fmt.Sprintf("%s has %d characters, over Windsurf's limit of %d; split into %d parts", filePath, utf8.RuneCountInString(body)+overhead, windsurfFileLimit, len(parts)))
+	}
+	return parts
+}
+
+// splitMarkdown splits text into parts of at most limit characters,
+// preferring to break before headings, then between paragraphs, then
+// between lines
+func splitMarkdown(text string, limit int) []string {
+	if utf8.RuneCountInString(text) <= limit {
+		return []string{text}
+	}
+
+	var parts []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			parts = append(parts, current.String())
+			current.Reset()
+		}
+	}
+	add := func(block string) {
+		if utf8.RuneCountInString(current.String())+utf8.RuneCountInString(block) > limit {
+			flush()
+		}
+		current.WriteString(block)
+	}
+
+	for _, section := range markdownSections(text) {
+		if utf8.RuneCountInString(section) <= limit {
+			add(section)
+			continue
+		}
+		for _, paragraph := range splitAfter(section, "\n\n") {
+			if utf8.RuneCountInString(paragraph) <= limit {
+				add(paragraph)
+				continue
+			}
+			for _, line := range splitAfter(paragraph, "\n") {
+				for utf8.RuneCountInString(line) > limit {
+					flush()
+					cut := runeOffset(line, limit)
+					parts = append(parts, line[:cut])
+					line = line[cut:]
+				}
+				add(line)
+			}
+		}
+	}
+	flush()
+	return parts
+}
+
+// markdownSections splits text before every heading outside code blocks
+func markdownSections(text string) []string {
+	var sections []string
+	var current strings.Builder
+	inCode := false
+	for _, line := range splitAfter(text, "\n") {
+		if strings.HasPrefix(line, "```") {
+			inCode = !inCode
+		}
+		if !inCode && strings.HasPrefix(line, "#") && current.Len() > 0 {
+			sections = append(sections, current.String())
+			current.Reset()
+		}
+		current.WriteString(line)
+	}
+	if current.Len() > 0 {
+		sections = append(sections, current.String())
+	}
+	return sections
+}
+
+// splitAfter splits text after every occurrence of sep, keeping sep at the
+// end of each piece
+func splitAfter(text, sep string) []string {
+	pieces := strings.SplitAfter(text, sep)
+	if pieces[len(pieces)-1] == "" {
+		pieces = pieces[:len(pieces)-1]
+	}
+	return pieces
+}
+
+// runeOffset returns the byte offset of the n-th rune of s
+func runeOffset(s string, n int) int {
+	for i := range s {
+		if n == 0 {
+			return i
+		}
+		n--
+	}
+	return len(s)
+}
@@ -0,0 +1,127 @@
+package providers
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/agentspack/agentspack/internal/dedupe"
+	"github.com/agentspack/agentspack/internal/output"
+	"github.com/agentspack/agentspack/internal/provenance"
+	"github.com/agentspack/agentspack/internal/version"
+	"github.com/agentspack/agentspack/internal/wizard"
+)
+
+func TestWindsurfGenerate(t *testing.T) {
+	config := &wizard.Config{Providers: []string{"windsurf"}, TechStacks: []string{"backend"}, GenerateBase: true}
+	plan := output.NewPlan()
+	if err := (&WindsurfProvider{}).Generate(config, writeTestTemplates(t), plan); err != nil {
+		t.Fatalf("Failed to generate: %v", err)
+	}
+
+	expected := map[string]string{
+		".windsurf/rules/base.md":                      "trigger: always_on\n",
+		".windsurf/rules/global.md":                    "trigger: always_on\n",
+		".windsurf/rules/backend.md":                   "globs: **/*.go, api/**\n",
+		".windsurf/rules/backend-migrations.md":        "globs: migrations/**\n",
+		".windsurf/rules/agent-ui-designer.md":         "trigger: model_decision\ndescription: \"Designs interfaces\"\n",
+		".windsurf/rules/agent-api-developer.md":       "description: \"Builds APIs\"\n",
+		".windsurf/workflows/planning-01-write-prd.md": "# PRD\n",
+		".windsurf/workflows/planning.md":              "/planning-02-write-todos",
+	}
+	for path, want := range expected {
+		f, ok := plan.Get(path)
+		if !ok {
+			t.Errorf("Expected %s to be planned", path)
+			continue
+		}
+		if !strings.Contains(string(f.Content), want) {
+			t.Errorf("Expected %s to contain %q, got:\n%s", path, want, f.Content)
+		}
+	}
+	if len(plan.Warnings()) != 0 {
+		t.Errorf("Expected no warnings, got %v", plan.Warnings())
+	}
+
+	// Windsurf reads only global rules from the home directory
+	config.Scope = wizard.ScopeUser
+	plan = output.NewPlan()
+	if err := (&WindsurfProvider{}).Generate(config, writeTestTemplates(t), plan); err != nil {
+		t.Fatalf("Failed to generate for the user scope: %v", err)
+	}
+	if _, ok := plan.Get(".codeium/windsurf/memories/global_rules.md"); !ok || plan.Len() != 1 {
+		t.Errorf("Expected only the global rules file in the user scope, got %d files", plan.Len())
+	}
+	if len(plan.Warnings()) != 1 {
+		t.Errorf("Expected a warning about the skipped content, got %v", plan.Warnings())
+	}
+}
+
+func TestWindsurfSplitsWithRoomForProvenance(t *testing.T) {
+	// Without headings or paragraphs, parts are filled up to the last line that fits
+	templates := map[string]string{
+		"system/rules/global/coding_style.md":      "## Coding style\n",
+		"system/workflows/planning/01_research.md": "# Research\n\n" + strings.Repeat("- Compare a competitor\n", 1200),
+	}
+	config := &wizard.Config{Providers: []string{"windsurf"}, Provenance: true}
+	plan := output.NewPlan()
+	if err := (&WindsurfProvider{}).Generate(config, writeTemplates(t, templates), plan); err != nil {
+		t.Fatalf("Failed to generate: %v", err)
+	}
+
+	// Annotate the files the way the generator does with --provenance
+	read := make(map[string][]byte, len(templates))
+	for name, data := range templates {
+		read[name] = []byte(data)
+	}
+	plan.Update(func(f *output.File) {
+		f.Content = provenance.Insert(f.Content, provenance.Header(*f, read, version.Version))
+		f.SourceMap = provenance.Map(f.Content, f.Sources, read)
+	})
+
+	first := ".windsurf/workflows/planning-01-research.md"
+	var parts int
+	for _, f := range plan.Files() {
+		if n := utf8.RuneCount(f.Content); n > windsurfFileLimit {
+			t.Errorf("Expected %s to fit Windsurf's limit with its provenance header, got %d characters", f.Path, n)
+		}
+		if f.Path == first || f.PartOf == first {
+			parts++
+		}
+	}
+	if parts != 3 {
+		t.Errorf("Expected the workflow to be split into 3 parts, got %d", parts)
+	}
+	if len(plan.Warnings()) != 1 {
+		t.Errorf("Expected a warning about the split, got %v", plan.Warnings())
+	}
+
+	// The parts of one template aren't duplicates of each other
+	if duplicates := dedupe.Find(plan); len(duplicates) != 0 {
+		t.Errorf("Expected no duplicates, got %+v", duplicates)
+	}
+}
+
+func TestSplitMarkdown(t *testing.T) {
+	section := "## Section\n\n" + strings.Repeat("word ", 30) + "\n\n```sh\n# not a heading\n```\n"
+	text := strings.Repeat(section, 4)
+
+	parts := splitMarkdown(text, 2*utf8.RuneCountInString(section))
+	if len(parts) != 2 {
+		t.Fatalf("Expected 2 parts, got %d", len(parts))
+	}
+	if strings.Join(parts, "") != text {
+		t.Error("Expected the parts to add up to the text")
+	}
+	for _, part := range parts {
+		if !strings.HasPrefix(part, "## Section") {
+			t.Errorf("Expected every part to start at a heading, got %q", part[:20])
+		}
+	}
+
+	// A single line longer than the limit is cut
+	parts = splitMarkdown(strings.Repeat("é", 25), 10)
+	if len(parts) != 3 || parts[2] != strings.Repeat("é", 5) {
+		t.Errorf("Expected a long line to be cut into 3 parts, got %q", parts)
+	}
+}
@@ -0,0 +1,187 @@
+package stacks
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/agentspack/agentspack/internal/content"
+	"github.com/agentspack/agentspack/internal/templates"
+	"gopkg.in/yaml.v3"
+)
+
+// RulesDir is the content directory stack manifests are searched in
+const RulesDir = "system/rules"
+
+// ManifestName is the file name that declares a tech stack
+const ManifestName = "stack.yaml"
+
+// Stack is a tech stack declared by a system/rules/<path>/stack.yaml manifest
+type Stack struct {
+	// Name is the identifier used in configs and on the command line (e.g. "react")
+	Name string `yaml:"name"`
+	// DisplayName is shown in the wizard (e.g. "React")
+	DisplayName string `yaml:"display_name"`
+	// SourcePath is the rules directory relative to system/rules (e.g. "frontend/react").
+	// Defaults to the directory containing the manifest.
+	SourcePath string `yaml:"source_path"`
+	// Globs are the default file patterns the stack's rules are scoped to
+	Globs []string `yaml:"globs"`
+	// RuleDescription prefixes the descriptions of individual rules (e.g. "React component")
+	RuleDescription string `yaml:"rule_description"`
+	// SkillDescription tells the agent when to load the stack's skill
+	SkillDescription string `yaml:"skill_description"`
+	// ShortDescription is a one-line summary of the stack's guidelines
+	ShortDescription string `yaml:"short_description"`
+
+	// Detect lists the project signals that indicate the stack is in use
+	Detect Detect `yaml:"detect"`
+	// Infer describes which source files belong to the stack
+	Infer Infer `yaml:"infer"`
+}
+
+// Detect lists the project signals that indicate a stack is in use
+type Detect struct {
+	// Dependencies are package.json dependencies (e.g. "react", "express")
+	Dependencies []string `yaml:"dependencies"`
+	// Files maps manifest file names to what they are (e.g. go.mod: Go module)
+	Files map[string]string `yaml:"files"`
+	// TSConfigJSX matches a tsconfig that compiles JSX for React
+	TSConfigJSX bool `yaml:"tsconfig_jsx"`
+}
+
+// Infer describes which source files belong to a stack
+type Infer struct {
+	// Extensions are the stack's source file extensions (e.g. ".tsx")
+	Extensions []string `yaml:"extensions"`
+	// Ambiguous maps an extension to other extensions; it is only attributed
+	// to the stack in source roots that contain none of them
+	Ambiguous map[string][]string `yaml:"ambiguous"`
+}
+
+// SkillName returns the name of the skill generated for the stack (e.g. "react-guidelines")
+func (s Stack) SkillName() string {
+	return s.Name + "-guidelines"
+}
+
+// Registry holds all tech stacks declared in the content filesystem
+type Registry struct {
+	stacks []Stack
+	byName map[string]Stack
+}
+
+// Load builds a registry from every stack manifest under system/rules
+func Load(fs content.FileSystem) (*Registry, error) {
+	manifests, err := findManifests(fs, RulesDir)
+	if err != nil {
+		return nil, err
+	}
+
+	registry := &Registry{byName: make(map[string]Stack)}
+	for _, manifest := range manifests {
+		stack, err := readManifest(fs, manifest)
+		if err != nil {
+			return nil, err
+		}
+		if existing, ok := registry.byName[stack.Name]; ok {
+			return nil, fmt.Errorf("tech stack %q is declared twice (%s and %s)", stack.Name, existing.SourcePath, stack.SourcePath)
+		}
+		registry.byName[stack.Name] = stack
+		registry.stacks = append(registry.stacks, stack)
+	}
+
+	sort.Slice(registry.stacks, func(i, j int) bool {
+		return registry.stacks[i].Name < registry.stacks[j].Name
+	})
+	return registry, nil
+}
+
+// Get retrieves a stack by name
+func (r *Registry) Get(name string) (Stack, bool) {
+	s, ok := r.byName[name]
+	return s, ok
+}
+
+// All returns every stack, sorted by name
+func (r *Registry) All() []Stack {
+	return r.stacks
+}
+
+// Names returns the names of every stack, sorted
+func (r *Registry) Names() []string {
+	names := make([]string, len(r.stacks))
+	for i, s := range r.stacks {
+		names[i] = s.Name
+	}
+	return names
+}
+
+// Select returns the named stacks in the given order, skipping unknown names
+func (r *Registry) Select(names []string) []Stack {
+	var selected []Stack
+	for _, name := range names {
+		if s, ok := r.byName[name]; ok {
+			selected = append(selected, s)
+		}
+	}
+	return selected
+}
+
+// findManifests recursively collects stack manifests below dir
+func findManifests(fs content.FileSystem, dir string) ([]string, error) {
+	entries, err := fs.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	var manifests []string
+	for _, entry := range entries {
+		entryPath := path.Join(dir, entry.Name())
+		if entry.IsDir() {
+			nested, err := findManifests(fs, entryPath)
+			if err != nil {
+				return nil, err
+			}
+			manifests = append(manifests, nested...)
+		} else if entry.Name() == ManifestName {
+			manifests = append(manifests, entryPath)
+		}
+	}
+	return manifests, nil
+}
+
+// readManifest parses a stack manifest and fills in defaults derived from its location
+func readManifest(fs content.FileSystem, manifest string) (Stack, error) {
+	data, err := fs.ReadFile(manifest)
+	if err != nil {
+		return Stack{}, err
+	}
+
+	var stack Stack
+	if err := yaml.Unmarshal(data, &stack); err != nil {
+		return Stack{}, fmt.Errorf("invalid stack manifest %s: %w", manifest, err)
+	}
+
+	dir := strings.TrimPrefix(path.Dir(manifest), RulesDir+"/")
+	if stack.SourcePath == "" {
+		stack.SourcePath = dir
+	}
+	if stack.Name == "" {
+		stack.Name = path.Base(dir)
+	}
+	if stack.DisplayName == "" {
+		stack.DisplayName = templates.NormalizeWorkflowName(stack.Name)
+	}
+	if stack.RuleDescription == "" {
+		stack.RuleDescription = stack.DisplayName
+	}
+	if stack.ShortDescription == "" {
+		stack.ShortDescription = fmt.Sprintf("%s development guidelines", stack.DisplayName)
+	}
+	if stack.SkillDescription == "" {
+		stack.SkillDescription = fmt.Sprintf("Best practices for %s development.", stack.DisplayName)
+	}
+
+	return stack, nil
+}
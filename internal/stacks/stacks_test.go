@@ -0,0 +1,71 @@
+package stacks
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/agentspack/agentspack/internal/content"
+)
+
+// writeManifest creates a stack manifest at system/rules/<dir>/stack.yaml under root
+func writeManifest(t *testing.T, root, dir, data string) {
+	t.Helper()
+	path := filepath.Join(root, "system", "rules", dir, ManifestName)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create dir for %s: %v", dir, err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatalf("Failed to write manifest for %s: %v", dir, err)
+	}
+}
+
+func TestLoadRepoManifests(t *testing.T) {
+	registry, err := Load(content.NewLocalFS(filepath.Join("..", "..")))
+	if err != nil {
+		t.Fatalf("Failed to load stacks: %v", err)
+	}
+
+	if names := registry.Names(); !reflect.DeepEqual(names, []string{"backend", "react"}) {
+		t.Errorf("Unexpected stacks: %v", names)
+	}
+
+	react, ok := registry.Get("react")
+	if !ok {
+		t.Fatal("Expected react stack to be declared")
+	}
+	if react.SourcePath != "frontend/react" || react.DisplayName != "React" || react.SkillName() != "react-guidelines" {
+		t.Errorf("Unexpected react stack: %+v", react)
+	}
+}
+
+func TestLoadFillsDefaultsFromLocation(t *testing.T) {
+	root := t.TempDir()
+	writeManifest(t, root, "frontend/vue", "globs: [\"**/*.vue\"]\n")
+
+	registry, err := Load(content.NewLocalFS(root))
+	if err != nil {
+		t.Fatalf("Failed to load stacks: %v", err)
+	}
+
+	vue, ok := registry.Get("vue")
+	if !ok {
+		t.Fatalf("Expected vue stack, got %v", registry.Names())
+	}
+	if vue.SourcePath != "frontend/vue" || vue.DisplayName != "Vue" || vue.RuleDescription != "Vue" {
+		t.Errorf("Unexpected defaults: %+v", vue)
+	}
+}
+
+func TestLoadRejectsDuplicateNames(t *testing.T) {
+	root := t.TempDir()
+	writeManifest(t, root, "a", "name: web\n")
+	writeManifest(t, root, "b", "name: web\n")
+
+	_, err := Load(content.NewLocalFS(root))
+	if err == nil || !strings.Contains(err.Error(), "declared twice") {
+		t.Fatalf("Expected duplicate stack error, got %v", err)
+	}
+}
@@ -0,0 +1,5 @@
+package version
+
+// Version is the agentspack version, set at build time with
+// -ldflags "-X github.com/agentspack/agentspack/internal/version.Version=..."
+var Version = "dev"
@@ -0,0 +1,100 @@
+// Package watch polls a template directory for changes, so it works
+// without file system notification support from the OS.
+package watch
+
+import (
+	"context"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// DefaultInterval is how often the directory is polled by default
+const DefaultInterval = time.Second
+
+// fileInfo is what a snapshot records about a file to notice changes
+type fileInfo struct {
+	size    int64
+	modTime time.Time
+}
+
+// Snapshot records the size and modification time of every file below a directory
+type Snapshot map[string]fileInfo
+
+// Take snapshots the files below dir. Paths are slash-separated and
+// prefixed with prefix (e.g. "system"), so they match template paths.
+func Take(dir, prefix string) (Snapshot, error) {
+	snapshot := make(Snapshot)
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		snapshot[path.Join(prefix, filepath.ToSlash(rel))] = fileInfo{size: info.Size(), modTime: info.ModTime()}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// Changed returns the sorted paths that were added, removed or modified since old
+func (s Snapshot) Changed(old Snapshot) []string {
+	var changed []string
+	for p, info := range s {
+		if before, ok := old[p]; !ok || before.size != info.size || !before.modTime.Equal(info.modTime) {
+			changed = append(changed, p)
+		}
+	}
+	for p := range old {
+		if _, ok := s[p]; !ok {
+			changed = append(changed, p)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// Poll snapshots dir every interval and calls onChange with the paths that
+// changed, until ctx is cancelled. Errors taking a snapshot are passed to
+// onError and polling continues, since editors briefly remove files while
+// saving them.
+func Poll(ctx context.Context, dir, prefix string, interval time.Duration, onChange func([]string), onError func(error)) error {
+	last, err := Take(dir, prefix)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		current, err := Take(dir, prefix)
+		if err != nil {
+			onError(err)
+			continue
+		}
+		if changed := current.Changed(last); len(changed) > 0 {
+			last = current
+			onChange(changed)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSnapshotChanged(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("rules/backend/apis.md", "apis")
+	write("agents/ui-designer.md", "designer")
+	write("agents/gone.md", "gone")
+
+	before, err := Take(dir, "system")
+	if err != nil {
+		t.Fatalf("Take failed: %v", err)
+	}
+	if _, ok := before["system/rules/backend/apis.md"]; !ok || len(before) != 3 {
+		t.Fatalf("unexpected snapshot %v", before)
+	}
+
+	write("rules/backend/apis.md", "apis, edited")
+	write("agents/new.md", "new")
+	if err := os.Remove(filepath.Join(dir, "agents", "gone.md")); err != nil {
+		t.Fatal(err)
+	}
+	// Touching a file without changing its size still counts as a change
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(filepath.Join(dir, "agents", "ui-designer.md"), later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	after, err := Take(dir, "system")
+	if err != nil {
+		t.Fatalf("Take failed: %v", err)
+	}
+	want := []string{"system/agents/gone.md", "system/agents/new.md", "system/agents/ui-designer.md", "system/rules/backend/apis.md"}
+	if got := after.Changed(before); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if got := after.Changed(after); len(got) != 0 {
+		t.Fatalf("expected no changes, got %v", got)
+	}
+}
@@ -18,6 +18,14 @@ const (
 	ClaudeCodeModeSkills ClaudeCodeMode = "skills"
 )
 
+// GeminiContext represents which context file Gemini CLI reads
+type GeminiContext string
+
+const (
+	GeminiContextGemini GeminiContext = "gemini" // GEMINI.md
+	GeminiContextAgents GeminiContext = "agents" // AGENTS.md, shared with Codex and Cursor
+)
+
 // SyncMode represents how changes should be applied to target repos
 type SyncMode string
 
@@ -26,38 +34,92 @@ const (
 	SyncModeMerge SyncMode = "merge"
 )
 
+// Scope represents where generated files are installed
+type Scope string
+
+const (
+	ScopeProject Scope = "project" // The output directory, usually a repository
+	ScopeUser    Scope = "user"    // The user's home directory (~/.claude, ~/.codex, ~/.cursor)
+)
+
 // Config holds the user's selections from the wizard
 type Config struct {
 	Providers      []string
 	TechStacks     []string
 	GenerateBase   bool           // Whether to generate the base file (CLAUDE.md, AGENTS.md, etc.)
 	OutputDir      string
+	Scope          Scope          // Empty means ScopeProject; with ScopeUser, OutputDir is the home directory
+	Provenance     bool           // Whether generated markdown files start with a comment naming their source templates
+	Dedupe         bool           // Whether files refer to a canonical file (e.g. AGENTS.md) instead of repeating its templates
 	ClaudeCodeMode ClaudeCodeMode // Only used when claude-code is selected
+	GeminiContext  GeminiContext  // Only used when gemini is selected
 
 	// GitHub sync options
 	SyncToGitHub bool     // Whether to sync generated files to GitHub repos
 	SyncMode     SyncMode // "pr" or "merge"
 	TargetBranch string   // Branch to create PR against or merge into (default: "main")
+
+	// Path scoping options
+	ProjectDir    string                  // Project to detect stacks and infer globs from (default: current directory)
+	InferGlobs    bool                    // Whether to infer stack globs from the project layout
+	StackGlobs    map[string]GlobOverride // Per-stack glob overrides, keyed by tech stack
+	InferredGlobs map[string][]string     // Filled in by the generator when InferGlobs is set
+
+	// Content selection by ID (see internal/catalog)
+	Agents    Selection // Agent templates, by file name (e.g. "visual-storyteller")
+	Workflows Selection // Workflows, by folder name (e.g. "planning")
+	Rules     Selection // Rule templates, by path under system/rules (e.g. "backend/database_queries")
 }
 
-// Available options
-var (
-	AvailableProviders = []huh.Option[string]{
-		huh.NewOption("Cursor", "cursor"),
-		huh.NewOption("Claude Code", "claude-code"),
-		huh.NewOption("Codex", "codex"),
-	}
+// IsUserScope reports whether files are installed into the user's home directory
+func (c *Config) IsUserScope() bool {
+	return c.Scope == ScopeUser
+}
 
-	AvailableTechStacks = []huh.Option[string]{
-		huh.NewOption("Backend", "backend"),
-		huh.NewOption("React", "react"),
+// Selection narrows down which templates of a kind are generated. When
+// Include is non-empty only those IDs are generated; Exclude is applied on top.
+// IDs are compared with underscores and hyphens treated alike.
+type Selection struct {
+	Include []string
+	Exclude []string
+}
+
+// Allows checks if the template with the given ID should be generated
+func (s Selection) Allows(id string) bool {
+	if len(s.Include) > 0 && !containsID(s.Include, id) {
+		return false
 	}
+	return !containsID(s.Exclude, id)
+}
 
-	ClaudeCodeModeOptions = []huh.Option[string]{
-		huh.NewOption("Rule files (always loaded, path-scoped)", string(ClaudeCodeModeRules)),
-		huh.NewOption("Skills (loaded on-demand by Claude)", string(ClaudeCodeModeSkills)),
+// IsEmpty reports whether the selection allows everything
+func (s Selection) IsEmpty() bool {
+	return len(s.Include) == 0 && len(s.Exclude) == 0
+}
+
+// SameID compares template IDs, treating underscores and hyphens alike
+func SameID(a, b string) bool {
+	return strings.ReplaceAll(a, "_", "-") == strings.ReplaceAll(b, "_", "-")
+}
+
+// containsID checks if id is in the list (see SameID)
+func containsID(ids []string, id string) bool {
+	for _, candidate := range ids {
+		if SameID(candidate, id) {
+			return true
+		}
 	}
+	return false
+}
+
+// GlobOverride replaces or extends the globs a tech stack's rules are scoped to
+type GlobOverride struct {
+	Replace []string // Used instead of the default or inferred globs when non-empty
+	Add     []string // Appended to the resulting globs
+}
 
+// Available options
+var (
 	SyncModeOptions = []huh.Option[string]{
 		huh.NewOption("Create Pull Request (for review)", string(SyncModePR)),
 		huh.NewOption("Merge directly to branch", string(SyncModeMerge)),
@@ -68,20 +130,144 @@ var (
 	SyncReposFile      = "sync_repos.md"
 )
 
-// Run executes the interactive wizard and returns the user's configuration
-func Run() (*Config, error) {
-	config := &Config{
+// ProviderChoice is a selectable provider and its follow-up questions
+type ProviderChoice struct {
+	Name        string
+	DisplayName string
+	Description string
+	Questions   []Question // Asked when the provider is selected
+}
+
+// Question is a provider-specific single-choice question
+type Question struct {
+	Title       string
+	Description string
+	Options     []huh.Option[string] // The first option is the default
+	Get         func(config *Config) string
+	Set         func(config *Config, value string)
+}
+
+// RunOptions customizes how the wizard is run
+type RunOptions struct {
+	// Providers are the selectable providers
+	Providers []ProviderChoice
+
+	// Defaults pre-fills the forms. When nil, the built-in defaults are used.
+	Defaults *Config
+
+	// Previous is the configuration of the last run into the target
+	// directory. When set, the wizard first offers to reuse it unchanged.
+	Previous *Config
+
+	// Profiles are offered on a first screen when non-empty. The option
+	// values are profile names passed to LoadProfile.
+	Profiles []huh.Option[string]
+
+	// LoadProfile expands a selected profile into a configuration that
+	// pre-fills the remaining forms
+	LoadProfile func(name string) (*Config, error)
+
+	// TechStacks are the selectable tech stacks, as declared by the
+	// templates' stack manifests
+	TechStacks []huh.Option[string]
+
+	// Agents, Workflows and Rules are the individually selectable templates
+	// (option values are catalog IDs). When any is non-empty, the wizard
+	// offers to deselect specific templates.
+	Agents    []huh.Option[string]
+	Workflows []huh.Option[string]
+	Rules     []huh.Option[string]
+
+	// DetectedStacks describes the evidence behind automatically detected
+	// tech stacks (e.g. "react: found react@18 in package.json"). It is shown
+	// alongside the tech stack question.
+	DetectedStacks []string
+}
+
+// DefaultConfig returns a configuration with the wizard's default answers
+func DefaultConfig() *Config {
+	return &Config{
 		OutputDir:    DefaultOutputDir,
 		GenerateBase: true, // default to yes
 	}
+}
+
+// RunWithOptions executes the interactive wizard with the given options
+func RunWithOptions(opts RunOptions) (*Config, error) {
+	config := DefaultConfig()
+	if opts.Defaults != nil {
+		*config = *opts.Defaults
+	}
+
+	// Step 0: Offer to reuse the previous run's answers
+	if opts.Previous != nil {
+		sameAsLastTime := true
+
+		previousForm := huh.NewForm(
+			huh.NewGroup(
+				huh.NewConfirm().
+					Title("Same as last time?").
+					Description(strings.TrimSpace(FormatSummary(opts.Previous))).
+					Affirmative("Yes, reuse").
+					Negative("No, change").
+					Value(&sameAsLastTime),
+			),
+		)
+
+		if err := previousForm.Run(); err != nil {
+			return nil, fmt.Errorf("wizard error: %w", err)
+		}
+
+		if sameAsLastTime {
+			previous := *opts.Previous
+			return &previous, nil
+		}
+	}
+
+	// Step 1: Optionally start from a profile
+	if len(opts.Profiles) > 0 && opts.LoadProfile != nil {
+		profileName := ""
+		profileOptions := append([]huh.Option[string]{huh.NewOption("None (choose everything manually)", "")}, opts.Profiles...)
+
+		profileForm := huh.NewForm(
+			huh.NewGroup(
+				huh.NewSelect[string]().
+					Title("Start from a profile?").
+					Description("Profiles pre-fill the following questions").
+					Options(profileOptions...).
+					Value(&profileName),
+			),
+		)
+
+		if err := profileForm.Run(); err != nil {
+			return nil, fmt.Errorf("wizard error: %w", err)
+		}
+
+		if profileName != "" {
+			profileConfig, err := opts.LoadProfile(profileName)
+			if err != nil {
+				return nil, err
+			}
+			*config = *profileConfig
+		}
+	}
+
+	// Step 2: Select providers
+	providerOptions := make([]huh.Option[string], len(opts.Providers))
+	for i, provider := range opts.Providers {
+		label := provider.DisplayName
+		if provider.Description != "" {
+			label = fmt.Sprintf("%s — %s", provider.DisplayName, provider.Description)
+		}
+		providerOptions[i] = huh.NewOption(label, provider.Name)
+	}
 
-	// Step 1: Select providers
 	providersForm := huh.NewForm(
 		huh.NewGroup(
 			huh.NewMultiSelect[string]().
 				Title("Select providers to generate for").
 				Description("Choose one or more AI coding providers").
-				Options(AvailableProviders...).
+				Options(providerOptions...).
 				Value(&config.Providers).
 				Validate(func(selected []string) error {
 					if len(selected) == 0 {
@@ -97,35 +283,31 @@ func Run() (*Config, error) {
 		return nil, fmt.Errorf("wizard error: %w", err)
 	}
 
-	// Step 2: If Claude Code was selected, ask about rules vs skills
-	if containsProvider(config.Providers, "claude-code") {
-		var modeStr string = string(ClaudeCodeModeRules) // default
-
-		claudeForm := huh.NewForm(
-			huh.NewGroup(
-				huh.NewSelect[string]().
-					Title("Claude Code: How should tech stack guidelines be generated?").
-					Description("Rules are always loaded; Skills are loaded on-demand when relevant").
-					Options(ClaudeCodeModeOptions...).
-					Value(&modeStr),
-			),
-		)
-
-		err = claudeForm.Run()
-		if err != nil {
-			return nil, fmt.Errorf("wizard error: %w", err)
+	// Step 3: Ask the follow-up questions of the selected providers
+	// (e.g. whether Claude Code should generate rules or skills)
+	for _, provider := range opts.Providers {
+		if !containsProvider(config.Providers, provider.Name) {
+			continue
+		}
+		for _, question := range provider.Questions {
+			if err := askQuestion(config, question); err != nil {
+				return nil, err
+			}
 		}
+	}
 
-		config.ClaudeCodeMode = ClaudeCodeMode(modeStr)
+	// Step 4: Select tech stacks, base file, and output directory
+	stacksDescription := "Choose the tech stacks to include templates for"
+	if len(opts.DetectedStacks) > 0 {
+		stacksDescription += "\nDetected in this project:\n  " + strings.Join(opts.DetectedStacks, "\n  ")
 	}
 
-	// Step 3: Select tech stacks, base file, and output directory
 	remainingForm := huh.NewForm(
 		huh.NewGroup(
 			huh.NewMultiSelect[string]().
 				Title("Select tech stacks").
-				Description("Choose the tech stacks to include templates for").
-				Options(AvailableTechStacks...).
+				Description(stacksDescription).
+				Options(opts.TechStacks...).
 				Value(&config.TechStacks).
 				Validate(func(selected []string) error {
 					if len(selected) == 0 {
@@ -161,9 +343,16 @@ func Run() (*Config, error) {
 	}
 
 	// Expand and clean the output path
-	config.OutputDir = expandPath(config.OutputDir)
+	config.OutputDir = ExpandPath(config.OutputDir)
+
+	// Step 5: Optionally pick individual agents, workflows and rules
+	if len(opts.Agents) > 0 || len(opts.Workflows) > 0 || len(opts.Rules) > 0 {
+		if err := runContentSelection(config, opts); err != nil {
+			return nil, err
+		}
+	}
 
-	// Step 4: GitHub sync options (only if sync_repos.md exists)
+	// Step 6: GitHub sync options (only if sync_repos.md exists)
 	if syncReposFileExists() {
 		syncForm := huh.NewForm(
 			huh.NewGroup(
@@ -182,7 +371,12 @@ func Run() (*Config, error) {
 		// If user wants to sync, ask for mode and branch
 		if config.SyncToGitHub {
 			var syncModeStr string = string(SyncModePR) // default to PR
-			config.TargetBranch = DefaultTargetBranch
+			if config.SyncMode != "" {
+				syncModeStr = string(config.SyncMode)
+			}
+			if config.TargetBranch == "" {
+				config.TargetBranch = DefaultTargetBranch
+			}
 
 			syncOptionsForm := huh.NewForm(
 				huh.NewGroup(
@@ -211,8 +405,124 @@ func Run() (*Config, error) {
 	return config, nil
 }
 
-// expandPath expands ~ to home directory and handles absolute paths
-func expandPath(path string) string {
+// askQuestion runs a provider question, pre-selecting the current answer
+func askQuestion(config *Config, question Question) error {
+	if len(question.Options) == 0 {
+		return nil
+	}
+
+	value := question.Get(config)
+	known := false
+	for _, opt := range question.Options {
+		known = known || opt.Value == value
+	}
+	if !known {
+		value = question.Options[0].Value
+	}
+
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title(question.Title).
+				Description(question.Description).
+				Options(question.Options...).
+				Value(&value),
+		),
+	)
+	if err := form.Run(); err != nil {
+		return fmt.Errorf("wizard error: %w", err)
+	}
+
+	question.Set(config, value)
+	return nil
+}
+
+// runContentSelection lets the user deselect individual templates. The
+// choices are stored as exclusions so templates added later are included.
+func runContentSelection(config *Config, opts RunOptions) error {
+	customize := !config.Agents.IsEmpty() || !config.Workflows.IsEmpty() || !config.Rules.IsEmpty()
+
+	confirmForm := huh.NewForm(
+		huh.NewGroup(
+			huh.NewConfirm().
+				Title("Choose individual agents, workflows and rules?").
+				Description("By default everything is generated").
+				Value(&customize),
+		),
+	)
+	if err := confirmForm.Run(); err != nil {
+		return fmt.Errorf("wizard error: %w", err)
+	}
+	if !customize {
+		config.Agents, config.Workflows, config.Rules = Selection{}, Selection{}, Selection{}
+		return nil
+	}
+
+	agents := allowedValues(opts.Agents, config.Agents)
+	workflows := allowedValues(opts.Workflows, config.Workflows)
+	rules := allowedValues(opts.Rules, config.Rules)
+
+	var groups []*huh.Group
+	if len(opts.Agents) > 0 {
+		groups = append(groups, huh.NewGroup(
+			huh.NewMultiSelect[string]().
+				Title("Select agents").
+				Options(opts.Agents...).
+				Value(&agents),
+		))
+	}
+	if len(opts.Workflows) > 0 {
+		groups = append(groups, huh.NewGroup(
+			huh.NewMultiSelect[string]().
+				Title("Select workflows").
+				Options(opts.Workflows...).
+				Value(&workflows),
+		))
+	}
+	if len(opts.Rules) > 0 {
+		groups = append(groups, huh.NewGroup(
+			huh.NewMultiSelect[string]().
+				Title("Select rules").
+				Description("Stack rules are only generated when their tech stack is selected").
+				Options(opts.Rules...).
+				Value(&rules),
+		))
+	}
+
+	if err := huh.NewForm(groups...).Run(); err != nil {
+		return fmt.Errorf("wizard error: %w", err)
+	}
+
+	config.Agents = excludeUnselected(opts.Agents, agents)
+	config.Workflows = excludeUnselected(opts.Workflows, workflows)
+	config.Rules = excludeUnselected(opts.Rules, rules)
+	return nil
+}
+
+// allowedValues returns the values of the options the selection allows
+func allowedValues(options []huh.Option[string], selection Selection) []string {
+	var values []string
+	for _, opt := range options {
+		if selection.Allows(opt.Value) {
+			values = append(values, opt.Value)
+		}
+	}
+	return values
+}
+
+// excludeUnselected builds a selection excluding every option not in selected
+func excludeUnselected(options []huh.Option[string], selected []string) Selection {
+	var selection Selection
+	for _, opt := range options {
+		if !containsID(selected, opt.Value) {
+			selection.Exclude = append(selection.Exclude, opt.Value)
+		}
+	}
+	return selection
+}
+
+// ExpandPath expands ~ to home directory and handles absolute paths
+func ExpandPath(path string) string {
 	// First clean the path
 	path = filepath.Clean(path)
 
@@ -254,21 +564,65 @@ func PrintSummary(config *Config) {
 	fmt.Println()
 	fmt.Println("=== Configuration Summary ===")
 	fmt.Println()
-	fmt.Printf("Providers:   %v\n", formatList(config.Providers))
-	fmt.Printf("Tech Stacks: %v\n", formatList(config.TechStacks))
-	fmt.Printf("Base file:   %v\n", boolToYesNo(config.GenerateBase))
-	fmt.Printf("Output:      %s\n", config.OutputDir)
+	fmt.Print(FormatSummary(config))
+	fmt.Println()
+}
+
+// FormatSummary returns the user's selections as aligned "Label: value" lines
+func FormatSummary(config *Config) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Providers:   %v\n", formatList(config.Providers)))
+	sb.WriteString(fmt.Sprintf("Tech Stacks: %v\n", formatList(config.TechStacks)))
+	sb.WriteString(fmt.Sprintf("Base file:   %v\n", boolToYesNo(config.GenerateBase)))
+	if config.IsUserScope() {
+		sb.WriteString("Output:      home directory (user scope)\n")
+	} else {
+		sb.WriteString(fmt.Sprintf("Output:      %s\n", config.OutputDir))
+	}
 	if containsProvider(config.Providers, "claude-code") {
-		fmt.Printf("Claude Code: %s mode\n", config.ClaudeCodeMode)
+		sb.WriteString(fmt.Sprintf("Claude Code: %s mode\n", config.ClaudeCodeMode))
+	}
+	if containsProvider(config.Providers, "gemini") {
+		contextFile := "GEMINI.md"
+		if config.GeminiContext == GeminiContextAgents {
+			contextFile = "AGENTS.md"
+		}
+		sb.WriteString(fmt.Sprintf("Gemini CLI:  reads %s\n", contextFile))
+	}
+	for _, line := range []struct {
+		label     string
+		selection Selection
+	}{
+		{"Agents:     ", config.Agents},
+		{"Workflows:  ", config.Workflows},
+		{"Rules:      ", config.Rules},
+	} {
+		if !line.selection.IsEmpty() {
+			sb.WriteString(fmt.Sprintf("%s %s\n", line.label, formatSelection(line.selection)))
+		}
 	}
 	if config.SyncToGitHub {
 		syncModeDesc := "PR"
 		if config.SyncMode == SyncModeMerge {
 			syncModeDesc = "merge"
 		}
-		fmt.Printf("GitHub Sync: Yes (%s to %s)\n", syncModeDesc, config.TargetBranch)
+		sb.WriteString(fmt.Sprintf("GitHub Sync: Yes (%s to %s)\n", syncModeDesc, config.TargetBranch))
 	}
-	fmt.Println()
+	return sb.String()
+}
+
+// formatSelection describes a non-empty selection (e.g. "all except a, b")
+func formatSelection(s Selection) string {
+	var parts []string
+	if len(s.Include) > 0 {
+		parts = append(parts, "only "+formatList(s.Include))
+	} else {
+		parts = append(parts, "all")
+	}
+	if len(s.Exclude) > 0 {
+		parts = append(parts, "except "+formatList(s.Exclude))
+	}
+	return strings.Join(parts, " ")
 }
 
 func boolToYesNo(b bool) string {